@@ -2,7 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/urfave/cli/v2"
 )
@@ -11,18 +10,21 @@ func ApplyCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "apply",
 		Usage: "Add annotations to files",
-		Flags: append(commonFlags,
-			&cli.BoolFlag{
-				Name:    "dry-run",
-				Aliases: []string{"n"},
-				Usage:   "Show what would be done without making changes",
-			},
+		Flags: append(append(append(commonFlags, dryRunFlags...), annotationFlags...),
 			&cli.BoolFlag{
 				Name:    "recursive",
 				Aliases: []string{"r"},
 				Usage:   "Process directories recursively",
 				Value:   true,
 			},
+			&cli.BoolFlag{
+				Name:  "preserve-mtime",
+				Usage: "Keep the modification time of files that are rewritten",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Annotate files that are skipped to protect checksums, such as applied Flyway migrations",
+			},
 		),
 		Action: runApply,
 	}
@@ -34,16 +36,12 @@ func runApply(c *cli.Context) error {
 		return fmt.Errorf("failed to initialize processor: %w", err)
 	}
 
-	files, err := proc.ListFiles()
-	if err != nil {
-		return fmt.Errorf("failed to list files: %w", err)
-	}
-
 	if c.Bool("dry-run") {
-		log.Printf("Would process %d files in %s", len(files), c.String("dir"))
-		for _, file := range files {
-			log.Printf("Would annotate: %s", file)
+		changes, err := proc.PlanApply()
+		if err != nil {
+			return fmt.Errorf("failed to plan changes: %w", err)
 		}
+		printChanges(c.App.Writer, changes, proc.Directory(), c.Bool("stat"))
 		return nil
 	}
 
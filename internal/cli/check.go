@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/krzko/codemap/internal/processor"
+	"github.com/urfave/cli/v2"
+)
+
+// checkFormats lists the output formats of the check command
+var checkFormats = []string{"text", "json", "sarif", "github"}
+
+func CheckCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "check",
+		Usage: "Report files with missing, stale or malformed annotations, exiting non-zero if there are any",
+		Flags: append(append(commonFlags, annotationFlags...),
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "Output format: " + strings.Join(checkFormats, ", "),
+				Value:   "text",
+			},
+		),
+		Action: runCheck,
+	}
+}
+
+func runCheck(c *cli.Context) error {
+	format := c.String("format")
+	var write func(io.Writer, []processor.Finding, string) error
+	switch format {
+	case "text":
+		write = writeCheckText
+	case "json":
+		write = writeCheckJSON
+	case "sarif":
+		write = writeCheckSARIF
+	case "github":
+		write = writeCheckGitHub
+	default:
+		return fmt.Errorf("invalid format %q (expected one of %s)", format, strings.Join(checkFormats, ", "))
+	}
+
+	proc, err := createProcessor(c)
+	if err != nil {
+		return fmt.Errorf("failed to initialize processor: %w", err)
+	}
+
+	findings, err := proc.Check()
+	if err != nil {
+		return fmt.Errorf("failed to check files: %w", err)
+	}
+
+	// Text output is read by people in the directory they ran codemap from,
+	// the machine formats by tools that expect repository-relative paths
+	base := proc.Directory()
+	if format != "text" {
+		base = proc.RepoRoot()
+	}
+	if err := write(c.App.Writer, findings, base); err != nil {
+		return fmt.Errorf("failed to write findings: %w", err)
+	}
+
+	if len(findings) > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// findingPath returns the path of a finding relative to base, with forward slashes
+func findingPath(f processor.Finding, base string) string {
+	rel, err := filepath.Rel(base, f.Path)
+	if err != nil {
+		return filepath.ToSlash(f.Path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func writeCheckText(w io.Writer, findings []processor.Finding, base string) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d: %s\n", findingPath(f, base), f.Line, f.Message); err != nil {
+			return err
+		}
+	}
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "All annotations are up to date")
+		return err
+	}
+	noun := "files need"
+	if len(findings) == 1 {
+		noun = "file needs"
+	}
+	_, err := fmt.Fprintf(w, "%d %s annotation updates\n", len(findings), noun)
+	return err
+}
+
+func writeCheckJSON(w io.Writer, findings []processor.Finding, base string) error {
+	type jsonFinding struct {
+		Path    string `json:"path"`
+		Line    int    `json:"line"`
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+	}
+
+	out := struct {
+		Findings []jsonFinding `json:"findings"`
+	}{Findings: []jsonFinding{}}
+	for _, f := range findings {
+		out.Findings = append(out.Findings, jsonFinding{
+			Path:    findingPath(f, base),
+			Line:    f.Line,
+			Kind:    string(f.Kind),
+			Message: f.Message,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// writeCheckGitHub emits GitHub Actions workflow commands, which show up as
+// annotations on the pull request
+func writeCheckGitHub(w io.Writer, findings []processor.Finding, base string) error {
+	for _, f := range findings {
+		_, err := fmt.Fprintf(w, "::error file=%s,line=%d,title=codemap %s annotation::%s\n",
+			githubEscapeProperty(findingPath(f, base)), f.Line, f.Kind, githubEscapeData(f.Message))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubEscapeData escapes the message of a workflow command
+func githubEscapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubEscapeProperty escapes a property value of a workflow command
+func githubEscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krzko/codemap/internal/processor"
+	"github.com/urfave/cli/v2"
+)
+
+// testFindings are one finding of each kind, under base
+func testFindings(base string) []processor.Finding {
+	return []processor.Finding{
+		{Path: filepath.Join(base, "a.py"), Line: 1, Kind: processor.FindingMissing, Message: "file has no codemap annotation"},
+		{Path: filepath.Join(base, "dir", "b,c:d.go"), Line: 2, Kind: processor.FindingStale, Message: "annotation is stale: pkg is \"a\", want \"b\""},
+		{Path: filepath.Join(base, "e.sh"), Line: 3, Kind: processor.FindingMalformed, Message: "malformed codemap annotation: 100%\nbroken"},
+	}
+}
+
+func TestWriteCheckText(t *testing.T) {
+	base := t.TempDir()
+	tests := []struct {
+		name     string
+		findings []processor.Finding
+		want     string
+	}{
+		{"none", nil, "All annotations are up to date\n"},
+		{"one", testFindings(base)[:1], "a.py:1: file has no codemap annotation\n1 file needs annotation updates\n"},
+		{"all", testFindings(base), "a.py:1: file has no codemap annotation\n" +
+			"dir/b,c:d.go:2: annotation is stale: pkg is \"a\", want \"b\"\n" +
+			"e.sh:3: malformed codemap annotation: 100%\nbroken\n" +
+			"3 files need annotation updates\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeCheckText(&buf, tt.findings, base); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeCheckText() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCheckJSON(t *testing.T) {
+	base := t.TempDir()
+	for _, findings := range [][]processor.Finding{nil, testFindings(base)} {
+		var buf bytes.Buffer
+		if err := writeCheckJSON(&buf, findings, base); err != nil {
+			t.Fatal(err)
+		}
+
+		var got struct {
+			Findings []map[string]any `json:"findings"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+		}
+		if got.Findings == nil || len(got.Findings) != len(findings) {
+			t.Fatalf("findings = %v, want %d", got.Findings, len(findings))
+		}
+		for i, f := range findings {
+			want := map[string]any{
+				"path":    findingPath(f, base),
+				"line":    float64(f.Line),
+				"kind":    string(f.Kind),
+				"message": f.Message,
+			}
+			if !reflect.DeepEqual(got.Findings[i], want) {
+				t.Errorf("finding %d = %v, want %v", i, got.Findings[i], want)
+			}
+		}
+	}
+}
+
+func TestWriteCheckSARIF(t *testing.T) {
+	base := t.TempDir()
+	findings := testFindings(base)
+	var buf bytes.Buffer
+	if err := writeCheckSARIF(&buf, findings, base); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name           string `json:"name"`
+					InformationURI string `json:"informationUri"`
+					Rules          []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string `json:"uri"`
+							URIBaseID string `json:"uriBaseId"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+
+	if log.Schema != "https://json.schemastore.org/sarif-2.1.0.json" || log.Version != "2.1.0" {
+		t.Errorf("$schema = %q, version = %q", log.Schema, log.Version)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("%d runs, want 1", len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "codemap" || run.Tool.Driver.InformationURI == "" {
+		t.Errorf("driver = %+v", run.Tool.Driver)
+	}
+
+	rules := map[string]bool{}
+	for _, r := range run.Tool.Driver.Rules {
+		if r.ShortDescription.Text == "" {
+			t.Errorf("rule %s has no description", r.ID)
+		}
+		rules[r.ID] = true
+	}
+	for _, kind := range []processor.FindingKind{processor.FindingMissing, processor.FindingStale, processor.FindingMalformed} {
+		if !rules["codemap/"+string(kind)] {
+			t.Errorf("no rule for %s", kind)
+		}
+	}
+
+	if len(run.Results) != len(findings) {
+		t.Fatalf("%d results, want %d", len(run.Results), len(findings))
+	}
+	for i, r := range run.Results {
+		f := findings[i]
+		if r.RuleID != "codemap/"+string(f.Kind) || r.Level != "error" || r.Message.Text != f.Message {
+			t.Errorf("result %d = %+v", i, r)
+		}
+		if len(r.Locations) != 1 {
+			t.Fatalf("result %d has %d locations", i, len(r.Locations))
+		}
+		loc := r.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URI != findingPath(f, base) || loc.ArtifactLocation.URIBaseID != "%SRCROOT%" || loc.Region.StartLine != f.Line {
+			t.Errorf("result %d location = %+v", i, loc)
+		}
+	}
+}
+
+func TestWriteCheckSARIFEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCheckSARIF(&buf, nil, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	// SARIF requires results to be an array, not null
+	if !strings.Contains(buf.String(), `"results": []`) {
+		t.Errorf("empty results not written as an array:\n%s", buf.String())
+	}
+}
+
+func TestWriteCheckGitHub(t *testing.T) {
+	base := t.TempDir()
+	var buf bytes.Buffer
+	if err := writeCheckGitHub(&buf, testFindings(base), base); err != nil {
+		t.Fatal(err)
+	}
+	want := "::error file=a.py,line=1,title=codemap missing annotation::file has no codemap annotation\n" +
+		"::error file=dir/b%2Cc%3Ad.go,line=2,title=codemap stale annotation::annotation is stale: pkg is \"a\", want \"b\"\n" +
+		"::error file=e.sh,line=3,title=codemap malformed annotation::malformed codemap annotation: 100%25%0Abroken\n"
+	if buf.String() != want {
+		t.Errorf("writeCheckGitHub() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// runCheckCommand runs codemap check with args and returns its output and
+// error, without exiting the test binary
+func runCheckCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	app := &cli.App{
+		Name:           "codemap",
+		Commands:       Commands(),
+		Writer:         &buf,
+		ExitErrHandler: func(*cli.Context, error) {},
+	}
+	err := app.Run(append([]string{"codemap", "check"}, args...))
+	return buf.String(), err
+}
+
+func TestCheckExitCode(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.py"), []byte("# codemap: v=1;path=a.py;lang=Python\nx = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"--dir", dir, "--fields", "path,lang"}
+
+	out, err := runCheckCommand(t, args...)
+	if err != nil {
+		t.Fatalf("check of annotated files = %v, want success\n%s", err, out)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "b.py"), []byte("x = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range checkFormats {
+		out, err := runCheckCommand(t, append(args, "--format", format)...)
+		var exit cli.ExitCoder
+		if !errors.As(err, &exit) || exit.ExitCode() != 1 {
+			t.Errorf("--format %s: error = %v, want exit code 1", format, err)
+		}
+		if !strings.Contains(out, "b.py") {
+			t.Errorf("--format %s: output does not mention b.py:\n%s", format, out)
+		}
+	}
+
+	_, err = runCheckCommand(t, append(args, "--format", "xml")...)
+	var exit cli.ExitCoder
+	if err == nil || errors.As(err, &exit) {
+		t.Errorf("invalid format: error = %v, want a usage error", err)
+	}
+}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/urfave/cli/v2"
 )
@@ -11,11 +10,14 @@ func CleanCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "clean",
 		Usage: "Remove annotations from files",
-		Flags: append(commonFlags,
+		Flags: append(append(commonFlags, dryRunFlags...),
 			&cli.BoolFlag{
-				Name:    "dry-run",
-				Aliases: []string{"n"},
-				Usage:   "Show what would be done without making changes",
+				Name:  "preserve-mtime",
+				Usage: "Keep the modification time of files that are rewritten",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Clean files that are skipped to protect checksums, such as applied Flyway migrations",
 			},
 		),
 		Action: runClean,
@@ -28,16 +30,12 @@ func runClean(c *cli.Context) error {
 		return fmt.Errorf("failed to initialize processor: %w", err)
 	}
 
-	files, err := proc.ListFiles()
-	if err != nil {
-		return fmt.Errorf("failed to list files: %w", err)
-	}
-
 	if c.Bool("dry-run") {
-		log.Printf("Would clean %d files in %s", len(files), c.String("dir"))
-		for _, file := range files {
-			log.Printf("Would clean: %s", file)
+		changes, err := proc.PlanClean()
+		if err != nil {
+			return fmt.Errorf("failed to plan changes: %w", err)
 		}
+		printChanges(c.App.Writer, changes, proc.Directory(), c.Bool("stat"))
 		return nil
 	}
 
@@ -1,12 +1,17 @@
 package cli
 
 import (
+	"strings"
+
+	"github.com/krzko/codemap/internal/languages"
+	"github.com/krzko/codemap/internal/processor"
 	"github.com/urfave/cli/v2"
 )
 
 func Commands() []*cli.Command {
 	return []*cli.Command{
 		ApplyCommand(),
+		CheckCommand(),
 		CleanCommand(),
 		ListCommand(),
 		StatsCommand(),
@@ -23,8 +28,7 @@ var commonFlags = []cli.Flag{
 	&cli.StringFlag{
 		Name:    "types",
 		Aliases: []string{"t"},
-		Usage:   "Comma-separated list of file types to process",
-		Value:   "go,py,js,jsx,ts,tsx",
+		Usage:   "Comma-separated list of file extensions or language names to process (default: all of " + strings.Join(languages.Names(), ", ") + ")",
 	},
 	&cli.BoolFlag{
 		Name:    "verbose",
@@ -32,3 +36,18 @@ var commonFlags = []cli.Flag{
 		Usage:   "Enable verbose logging",
 	},
 }
+
+// annotationFlags control what goes into an annotation, and are shared by the
+// commands that write or verify annotations
+var annotationFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "path-mode",
+		Usage: "How paths are written: relative (to --dir), repo (to the git root), absolute or import",
+		Value: string(processor.PathModeRelative),
+	},
+	&cli.StringFlag{
+		Name:  "fields",
+		Usage: "Comma-separated list of annotation fields, in the order they are written (available: " + strings.Join(processor.FieldNames(), ", ") + ")",
+		Value: strings.Join(processor.DefaultFields, ","),
+	},
+}
@@ -14,19 +14,37 @@ func createProcessor(c *cli.Context) (*processor.Processor, error) {
 	opts.Directory = c.String("dir")
 	opts.Recursive = c.Bool("recursive")
 	opts.Verbose = c.Bool("verbose")
+	opts.PreserveMtime = c.Bool("preserve-mtime")
+	opts.Force = c.Bool("force")
 
 	// Parse file types
 	if types := c.String("types"); types != "" {
 		typeList := strings.Split(types, ",")
 		for i, t := range typeList {
-			t = strings.TrimSpace(t)
+			t = strings.ToLower(strings.TrimSpace(t))
 			if !strings.HasPrefix(t, ".") {
-				typeList[i] = "." + t
+				t = "." + t
 			}
+			typeList[i] = t
 		}
 		opts.SupportedTypes = typeList
 	}
 
+	if mode := c.String("path-mode"); mode != "" {
+		pathMode, err := processor.ParsePathMode(mode)
+		if err != nil {
+			return nil, err
+		}
+		opts.PathMode = pathMode
+	}
+
+	if fields := c.String("fields"); fields != "" {
+		opts.Fields = nil
+		for _, f := range strings.Split(fields, ",") {
+			opts.Fields = append(opts.Fields, strings.TrimSpace(f))
+		}
+	}
+
 	if c.Bool("verbose") {
 		log.SetFlags(log.Ltime | log.Lshortfile)
 	} else {
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+
+	"github.com/krzko/codemap/internal/diff"
+	"github.com/krzko/codemap/pkg/annotator"
+	"github.com/urfave/cli/v2"
+)
+
+// dryRunFlags are shared by the commands that support --dry-run
+var dryRunFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:    "dry-run",
+		Aliases: []string{"n"},
+		Usage:   "Print a unified diff of the changes instead of making them",
+	},
+	&cli.BoolFlag{
+		Name:  "stat",
+		Usage: "With --dry-run, print a summary of changed lines per file instead of a diff",
+	},
+}
+
+// printChanges writes a unified diff, or a diffstat if stat is set, for the
+// changes that modify a file. Paths are shown relative to dir.
+func printChanges(w io.Writer, changes []*annotator.Change, dir string, stat bool) {
+	type statLine struct {
+		path     string
+		ins, del int
+	}
+	var stats []statLine
+	width := 0
+
+	for _, change := range changes {
+		if change.Result == annotator.Skipped {
+			log.Printf("Skipping file (%s): %s", change.Reason, change.Path)
+			continue
+		}
+		if !change.Changed() {
+			continue
+		}
+
+		relPath, err := filepath.Rel(dir, change.Path)
+		if err != nil {
+			relPath = change.Path
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if !stat {
+			fmt.Fprint(w, diff.Unified("a/"+relPath, "b/"+relPath, change.Before, change.After))
+			continue
+		}
+		ins, del := diff.Count(change.Before, change.After)
+		stats = append(stats, statLine{relPath, ins, del})
+		if len(relPath) > width {
+			width = len(relPath)
+		}
+	}
+
+	if !stat {
+		return
+	}
+
+	insertions, deletions := 0, 0
+	for _, s := range stats {
+		insertions += s.ins
+		deletions += s.del
+		fmt.Fprintf(w, " %-*s | %d %s\n", width, s.path, s.ins+s.del, statBar(s.ins, s.del))
+	}
+
+	noun := "files"
+	if len(stats) == 1 {
+		noun = "file"
+	}
+	fmt.Fprintf(w, " %d %s changed, %d insertions(+), %d deletions(-)\n", len(stats), noun, insertions, deletions)
+}
+
+// statBar renders the +/- bar of a diffstat line
+func statBar(insertions, deletions int) string {
+	bar := make([]byte, 0, insertions+deletions)
+	for i := 0; i < insertions; i++ {
+		bar = append(bar, '+')
+	}
+	for i := 0; i < deletions; i++ {
+		bar = append(bar, '-')
+	}
+	return string(bar)
+}
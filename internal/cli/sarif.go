@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/krzko/codemap/internal/processor"
+)
+
+// The subset of SARIF 2.1.0 needed to report check findings
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifRules describes one rule per finding kind
+var sarifRules = []sarifRule{
+	{ID: "codemap/" + string(processor.FindingMissing), ShortDescription: sarifMessage{Text: "File has no codemap annotation"}},
+	{ID: "codemap/" + string(processor.FindingStale), ShortDescription: sarifMessage{Text: "Codemap annotation no longer matches the file"}},
+	{ID: "codemap/" + string(processor.FindingMalformed), ShortDescription: sarifMessage{Text: "Codemap annotation cannot be parsed"}},
+}
+
+func writeCheckSARIF(w io.Writer, findings []processor.Finding, base string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "codemap",
+			InformationURI: "https://github.com/krzko/codemap",
+			Rules:          sarifRules,
+		}},
+		Results: []sarifResult{},
+	}
+
+	for _, f := range findings {
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  "codemap/" + string(f.Kind),
+			Level:   "error",
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: findingPath(f, base), URIBaseID: "%SRCROOT%"},
+				Region:           sarifRegion{StartLine: f.Line},
+			}}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/urfave/cli/v2"
 )
@@ -33,8 +34,13 @@ func runStats(c *cli.Context) error {
 	fmt.Printf("Files without annotations: %d\n", stats.UnannotatedFiles)
 
 	fmt.Println("\nBreakdown by language:")
-	for lang, count := range stats.FilesByLanguage {
-		fmt.Printf("  %s: %d files\n", lang, count)
+	langs := make([]string, 0, len(stats.FilesByLanguage))
+	for lang := range stats.FilesByLanguage {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		fmt.Printf("  %s: %d files\n", lang, stats.FilesByLanguage[lang])
 	}
 
 	return nil
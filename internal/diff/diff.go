@@ -0,0 +1,243 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change
+const context = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opInsert
+	opDelete
+)
+
+// edit is one line of an edit script turning old into new
+type edit struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a unified diff turning old into new, with the given file
+// names in the header, or "" if old and new are equal
+func Unified(oldName, newName, old, new string) string {
+	if old == new {
+		return ""
+	}
+
+	edits := compute(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(edits) {
+		h.write(&b)
+	}
+	return b.String()
+}
+
+// Count returns the number of lines inserted and deleted between old and new
+func Count(old, new string) (insertions, deletions int) {
+	if old == new {
+		return 0, 0
+	}
+	for _, e := range compute(splitLines(old), splitLines(new)) {
+		switch e.kind {
+		case opInsert:
+			insertions++
+		case opDelete:
+			deletions++
+		}
+	}
+	return insertions, deletions
+}
+
+// splitLines splits s into lines, keeping their terminators
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// compute returns the shortest edit script from a to b using Myers' algorithm.
+// Its cost grows with the number of differences rather than the file size,
+// which suits codemap's one-line changes to large files.
+func compute(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the path
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{opEqual, a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{opInsert, b[y-1]})
+				y--
+			} else {
+				edits = append(edits, edit{opDelete, a[x-1]})
+				x--
+			}
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// hunk is a run of edits with its position in the old and new files
+type hunk struct {
+	oldStart, newStart int // zero-based line of the first edit
+	edits              []edit
+}
+
+// hunks groups edits into hunks with context lines, merging hunks whose
+// context would overlap
+func hunks(edits []edit) []hunk {
+	var result []hunk
+	first := -1      // index in edits where the open hunk starts, -1 if none
+	lastChange := -1 // index of the latest insert or delete
+	prevEnd := 0     // index just past the previous hunk
+	var oldStart, newStart int
+
+	closeHunk := func() {
+		end := lastChange + context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+		result = append(result, hunk{oldStart: oldStart, newStart: newStart, edits: edits[first:end]})
+		first = -1
+		prevEnd = end
+	}
+
+	oldLine, newLine := 0, 0
+	for i, e := range edits {
+		if e.kind != opEqual {
+			if first >= 0 && i-lastChange > 2*context {
+				closeHunk()
+			}
+			if first < 0 {
+				// Lead in with up to context unchanged lines
+				back := 0
+				for back < context && i-back-1 >= prevEnd {
+					back++
+				}
+				first = i - back
+				oldStart, newStart = oldLine-back, newLine-back
+			}
+			lastChange = i
+		}
+
+		switch e.kind {
+		case opEqual:
+			oldLine++
+			newLine++
+		case opDelete:
+			oldLine++
+		case opInsert:
+			newLine++
+		}
+	}
+
+	if first >= 0 {
+		closeHunk()
+	}
+	return result
+}
+
+// write renders the hunk in unified format
+func (h hunk) write(b *strings.Builder) {
+	oldCount, newCount := 0, 0
+	for _, e := range h.edits {
+		if e.kind != opInsert {
+			oldCount++
+		}
+		if e.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(h.oldStart, oldCount), hunkRange(h.newStart, newCount))
+	for _, e := range h.edits {
+		switch e.kind {
+		case opEqual:
+			b.WriteString(" ")
+		case opInsert:
+			b.WriteString("+")
+		case opDelete:
+			b.WriteString("-")
+		}
+		b.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start,count pair of a hunk header. As in GNU diff, a
+// count of one is left out and an empty range refers to the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
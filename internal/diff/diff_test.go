@@ -0,0 +1,183 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "insert first line",
+			old:  "a\nb\n",
+			new:  "h\na\nb\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,2 +1,3 @@\n+h\n a\n b\n",
+		},
+		{
+			name: "remove first line",
+			old:  "h\na\nb\n",
+			new:  "a\nb\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,3 +1,2 @@\n-h\n a\n b\n",
+		},
+		{
+			name: "replace line",
+			old:  "a\nb\nc\n",
+			new:  "a\nx\nc\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "empty old file",
+			old:  "",
+			new:  "h\n",
+			want: "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+h\n",
+		},
+		{
+			name: "empty new file",
+			old:  "h\n",
+			new:  "",
+			want: "--- a/f\n+++ b/f\n@@ -1 +0,0 @@\n-h\n",
+		},
+		{
+			name: "no newline at end of old file",
+			old:  "a",
+			new:  "h\na",
+			want: "--- a/f\n+++ b/f\n@@ -1 +1,2 @@\n+h\n a\n\\ No newline at end of file\n",
+		},
+		{
+			name: "newline added at end of file",
+			old:  "a\nb",
+			new:  "a\nb\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "insert after a line without newline",
+			old:  "a",
+			new:  "a\nh\n",
+			want: "--- a/f\n+++ b/f\n@@ -1 +1,2 @@\n-a\n\\ No newline at end of file\n+a\n+h\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a/f", "b/f", tt.old, tt.new); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// numbered returns n lines "1\n" to "n\n"
+func numbered(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%d\n", i+1)
+	}
+	return lines
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	lines := numbered(20)
+
+	t.Run("context is limited", func(t *testing.T) {
+		changed := append([]string(nil), lines...)
+		changed[9] = "x\n"
+		got := Unified("a", "b", strings.Join(lines, ""), strings.Join(changed, ""))
+		want := "--- a\n+++ b\n@@ -7,7 +7,7 @@\n 7\n 8\n 9\n-10\n+x\n 11\n 12\n 13\n"
+		if got != want {
+			t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("distant changes get separate hunks", func(t *testing.T) {
+		changed := append([]string(nil), lines...)
+		changed[1] = "x\n"
+		changed[17] = "y\n"
+		got := Unified("a", "b", strings.Join(lines, ""), strings.Join(changed, ""))
+		want := "--- a\n+++ b\n" +
+			"@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n 4\n 5\n" +
+			"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+y\n 19\n 20\n"
+		if got != want {
+			t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("nearby changes share a hunk", func(t *testing.T) {
+		changed := append([]string(nil), lines...)
+		changed[4] = "x\n"
+		changed[10] = "y\n"
+		got := Unified("a", "b", strings.Join(lines, ""), strings.Join(changed, ""))
+		if n := strings.Count(got, "@@ -"); n != 1 {
+			t.Errorf("got %d hunks, want 1:\n%s", n, got)
+		}
+		if !strings.Contains(got, "@@ -2,13 +2,13 @@\n") {
+			t.Errorf("unexpected hunk header:\n%s", got)
+		}
+	})
+
+	t.Run("insertion shifts new line numbers", func(t *testing.T) {
+		changed := append([]string{"h\n"}, lines...)
+		changed[15] = "y\n" // old line 15
+		got := Unified("a", "b", strings.Join(lines, ""), strings.Join(changed, ""))
+		if !strings.Contains(got, "@@ -1,3 +1,4 @@\n+h\n") || !strings.Contains(got, "@@ -12,7 +13,7 @@\n") {
+			t.Errorf("unexpected hunk headers:\n%s", got)
+		}
+	})
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		old, new         string
+		wantIns, wantDel int
+	}{
+		{"a\n", "a\n", 0, 0},
+		{"a\n", "h\na\n", 1, 0},
+		{"h\na\n", "a\n", 0, 1},
+		{"a\nb\nc\n", "a\nx\nc\n", 1, 1},
+		{"", "a\nb\n", 2, 0},
+	}
+
+	for _, tt := range tests {
+		ins, del := Count(tt.old, tt.new)
+		if ins != tt.wantIns || del != tt.wantDel {
+			t.Errorf("Count(%q, %q) = %d, %d, want %d, %d", tt.old, tt.new, ins, del, tt.wantIns, tt.wantDel)
+		}
+	}
+}
+
+func TestComputeIsMinimal(t *testing.T) {
+	a := strings.SplitAfter("a\nb\nc\na\nb\nb\na\n", "\n")
+	b := strings.SplitAfter("c\nb\na\nb\na\nc\n", "\n")
+	edits := compute(a[:len(a)-1], b[:len(b)-1])
+
+	changes := 0
+	var gotA, gotB []string
+	for _, e := range edits {
+		if e.kind != opEqual {
+			changes++
+		}
+		if e.kind != opInsert {
+			gotA = append(gotA, e.line)
+		}
+		if e.kind != opDelete {
+			gotB = append(gotB, e.line)
+		}
+	}
+	// The classic example from Myers' paper has a shortest edit script of 5
+	if changes != 5 {
+		t.Errorf("edit script has %d changes, want 5", changes)
+	}
+	if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+		t.Errorf("edit script does not reproduce its inputs")
+	}
+}
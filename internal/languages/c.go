@@ -0,0 +1,42 @@
+package languages
+
+// C covers C sources and headers. Headers use the .h extension, which C++
+// projects share. The header is a block comment, as line comments are not
+// valid C89 and fail builds with -std=c89 -pedantic-errors.
+type C struct{}
+
+func init() {
+	Register(&C{})
+}
+
+func (c *C) Name() string {
+	return "C"
+}
+
+func (c *C) FileExtensions() []string {
+	return []string{".c", ".h"}
+}
+
+func (c *C) FileNames() []string {
+	return nil
+}
+
+func (c *C) CommentStart() string {
+	return "/*"
+}
+
+func (c *C) CommentEnd() string {
+	return "*/"
+}
+
+func (c *C) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment always returns false. Compilers recognise an include guard
+// when only comments come before its #ifndef, and #pragma once can go
+// anywhere, so a header on the first line keeps both working. Inserting it
+// after either line could split an #ifndef from its #define.
+func (c *C) IsSpecialComment(line string) bool {
+	return false
+}
@@ -0,0 +1,38 @@
+package languages
+
+// CPP covers C++ sources and headers. It places the header like C, but as a
+// line comment.
+type CPP struct{}
+
+func init() {
+	Register(&CPP{})
+}
+
+func (c *CPP) Name() string {
+	return "C++"
+}
+
+func (c *CPP) FileExtensions() []string {
+	return []string{".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx"}
+}
+
+func (c *CPP) FileNames() []string {
+	return nil
+}
+
+func (c *CPP) CommentStart() string {
+	return "//"
+}
+
+func (c *CPP) CommentEnd() string {
+	return ""
+}
+
+func (c *CPP) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment always returns false, for the reasons given on C
+func (c *CPP) IsSpecialComment(line string) bool {
+	return false
+}
@@ -0,0 +1,38 @@
+package languages
+
+type CSharp struct{}
+
+func init() {
+	Register(&CSharp{})
+}
+
+func (c *CSharp) Name() string {
+	return "C#"
+}
+
+func (c *CSharp) FileExtensions() []string {
+	return []string{".cs"}
+}
+
+func (c *CSharp) FileNames() []string {
+	return nil
+}
+
+func (c *CSharp) CommentStart() string {
+	return "//"
+}
+
+func (c *CSharp) CommentEnd() string {
+	return ""
+}
+
+func (c *CSharp) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment always returns false. Analyzers look for an
+// <auto-generated> comment anywhere in the leading comments, so a header
+// above it leaves generated files recognised.
+func (c *CSharp) IsSpecialComment(line string) bool {
+	return false
+}
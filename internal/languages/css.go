@@ -0,0 +1,39 @@
+package languages
+
+import "strings"
+
+// CSS only has /* */ comments
+type CSS struct{}
+
+func init() {
+	Register(&CSS{})
+}
+
+func (c *CSS) Name() string {
+	return "CSS"
+}
+
+func (c *CSS) FileExtensions() []string {
+	return []string{".css"}
+}
+
+func (c *CSS) FileNames() []string {
+	return nil
+}
+
+func (c *CSS) CommentStart() string {
+	return "/*"
+}
+
+func (c *CSS) CommentEnd() string {
+	return "*/"
+}
+
+func (c *CSS) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment keeps @charset first, as it is ignored anywhere else
+func (c *CSS) IsSpecialComment(line string) bool {
+	return strings.HasPrefix(line, "@charset ")
+}
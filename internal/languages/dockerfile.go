@@ -1,23 +1,41 @@
 package languages
 
+import "regexp"
+
+// parserDirective matches the BuildKit parser directives, which are only
+// honoured while they are the very first lines of a Dockerfile
+var parserDirective = regexp.MustCompile(`(?i)^#\s*(syntax|escape|check)\s*=`)
+
 type Dockerfile struct{}
 
+func init() {
+	Register(&Dockerfile{})
+}
+
+func (d *Dockerfile) Name() string {
+	return "Dockerfile"
+}
+
 func (d *Dockerfile) FileExtensions() []string {
-    return []string{".dockerfile", ""}  // Empty string for files named exactly "Dockerfile"
+	return []string{".dockerfile"}
+}
+
+func (d *Dockerfile) FileNames() []string {
+	return []string{"Dockerfile", "Dockerfile.*", "Containerfile", "Containerfile.*"}
 }
 
 func (d *Dockerfile) CommentStart() string {
-    return "#"
+	return "#"
 }
 
 func (d *Dockerfile) CommentEnd() string {
-    return ""
+	return ""
 }
 
 func (d *Dockerfile) MultiLineCommentStart() string {
-    return "#"
+	return "#"
 }
 
 func (d *Dockerfile) IsSpecialComment(line string) bool {
-    return false
-}
\ No newline at end of file
+	return parserDirective.MatchString(line)
+}
@@ -1,13 +1,23 @@
 package languages
 
-import "strings"
-
 type GoLang struct{}
 
+func init() {
+	Register(&GoLang{})
+}
+
+func (g *GoLang) Name() string {
+	return "Go"
+}
+
 func (g *GoLang) FileExtensions() []string {
 	return []string{".go"}
 }
 
+func (g *GoLang) FileNames() []string {
+	return nil
+}
+
 func (g *GoLang) CommentStart() string {
 	return "//"
 }
@@ -20,18 +30,9 @@ func (g *GoLang) MultiLineCommentStart() string {
 	return "/*"
 }
 
+// IsSpecialComment always returns false. Build constraints and generated code
+// markers only have to precede the package clause, and a build constraint must
+// be followed by a blank line, so the header is safest on the very first line.
 func (g *GoLang) IsSpecialComment(line string) bool {
-	specialPrefixes := []string{
-		"//go:generate",
-		"//go:build",
-		"//nolint",
-		"// +build",
-	}
-
-	for _, prefix := range specialPrefixes {
-		if strings.HasPrefix(strings.TrimSpace(line), prefix) {
-			return true
-		}
-	}
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,40 @@
+package languages
+
+// Groovy covers Groovy sources and Jenkins pipelines
+type Groovy struct{}
+
+func init() {
+	Register(&Groovy{})
+}
+
+func (g *Groovy) Name() string {
+	return "Groovy"
+}
+
+func (g *Groovy) FileExtensions() []string {
+	return []string{".groovy", ".gvy", ".jenkinsfile"}
+}
+
+func (g *Groovy) FileNames() []string {
+	return []string{"Jenkinsfile", "Jenkinsfile.*"}
+}
+
+func (g *Groovy) Interpreters() []string {
+	return []string{"groovy"}
+}
+
+func (g *Groovy) CommentStart() string {
+	return "//"
+}
+
+func (g *Groovy) CommentEnd() string {
+	return ""
+}
+
+func (g *Groovy) MultiLineCommentStart() string {
+	return "/*"
+}
+
+func (g *Groovy) IsSpecialComment(line string) bool {
+	return isShebang(line)
+}
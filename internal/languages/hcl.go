@@ -0,0 +1,19 @@
+package languages
+
+// HCL covers other HashiCorp configuration files, such as terragrunt.hcl and
+// Packer templates, which share Terraform's syntax
+type HCL struct {
+	Terraform
+}
+
+func init() {
+	Register(&HCL{})
+}
+
+func (h *HCL) Name() string {
+	return "HCL"
+}
+
+func (h *HCL) FileExtensions() []string {
+	return []string{".hcl"}
+}
@@ -0,0 +1,92 @@
+package languages
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Helm covers the templates of a Helm chart. The header is a template comment,
+// which Helm drops when rendering, rather than a YAML comment.
+type Helm struct {
+	mu     sync.Mutex
+	charts map[string]bool // directory -> whether it is in a chart's templates
+}
+
+func init() {
+	Register(&Helm{})
+}
+
+func (h *Helm) Name() string {
+	return "Helm"
+}
+
+func (h *Helm) FileExtensions() []string {
+	return nil
+}
+
+func (h *Helm) FileNames() []string {
+	return nil
+}
+
+// MatchesPath reports whether path is a template of a chart: a .yaml, .yml,
+// .tpl or NOTES.txt file below a templates directory next to Chart.yaml.
+// The search stops at the root of a git repository and is cached by
+// directory, as every YAML file in a tree is asked about.
+func (h *Helm) MatchesPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".tpl":
+	default:
+		if filepath.Base(path) != "NOTES.txt" {
+			return false
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.inTemplates(filepath.Dir(path))
+}
+
+// inTemplates reports whether dir is the templates directory of a chart or
+// below one
+func (h *Helm) inTemplates(dir string) bool {
+	if in, ok := h.charts[dir]; ok {
+		return in
+	}
+
+	in := false
+	if filepath.Base(dir) == "templates" && exists(filepath.Join(filepath.Dir(dir), "Chart.yaml")) {
+		in = true
+	} else if parent := filepath.Dir(dir); parent != dir && !exists(filepath.Join(dir, ".git")) {
+		in = h.inTemplates(parent)
+	}
+
+	if h.charts == nil {
+		h.charts = make(map[string]bool)
+	}
+	h.charts[dir] = in
+	return in
+}
+
+// exists reports whether there is a file or directory at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (h *Helm) CommentStart() string {
+	return "{{/*"
+}
+
+func (h *Helm) CommentEnd() string {
+	return "*/}}"
+}
+
+func (h *Helm) MultiLineCommentStart() string {
+	return "{{/*"
+}
+
+func (h *Helm) IsSpecialComment(line string) bool {
+	return false
+}
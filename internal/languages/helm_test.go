@@ -0,0 +1,120 @@
+package languages
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHelmMatchesPath(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"charts/web/Chart.yaml":                       "apiVersion: v2\nname: web\n",
+		"charts/web/values.yaml":                      "replicas: 1\n",
+		"charts/web/templates/deployment.yaml":        "kind: Deployment\n",
+		"charts/web/templates/_helpers.tpl":           "{{- define \"web.name\" -}}{{- end -}}\n",
+		"charts/web/templates/NOTES.txt":              "Installed {{ .Release.Name }}\n",
+		"charts/web/templates/README.txt":             "",
+		"charts/web/templates/tests/test-conn.yml":    "kind: Pod\n",
+		"charts/web/charts/db/Chart.yaml":             "apiVersion: v2\nname: db\n",
+		"charts/web/charts/db/templates/service.yaml": "kind: Service\n",
+		"k8s/templates/service.yaml":                  "kind: Service\n",
+		"k8s/deployment.yaml":                         "kind: Deployment\n",
+		".github/workflows/ci.yml":                    "on: push\n",
+	}
+	for name, content := range files {
+		writeFile(t, root, name, content)
+	}
+
+	tests := []struct {
+		path, want string
+	}{
+		{"charts/web/templates/deployment.yaml", "Helm"},
+		{"charts/web/templates/_helpers.tpl", "Helm"},
+		{"charts/web/templates/NOTES.txt", "Helm"},
+		{"charts/web/templates/tests/test-conn.yml", "Helm"},
+		{"charts/web/charts/db/templates/service.yaml", "Helm"},
+		{"charts/web/templates/README.txt", ""},
+		{"charts/web/Chart.yaml", "YAML"},
+		{"charts/web/values.yaml", "YAML"},
+		{"k8s/templates/service.yaml", "YAML"},
+		{"k8s/deployment.yaml", "YAML"},
+		{".github/workflows/ci.yml", "YAML"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := languageOf(filepath.Join(root, filepath.FromSlash(tt.path))); got != tt.want {
+				t.Errorf("ForPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelmMatchesPathStopsAtRepository(t *testing.T) {
+	// A repository checked out into the templates directory of a chart is
+	// not part of the chart
+	outer := t.TempDir()
+	writeFile(t, outer, "Chart.yaml", "apiVersion: v2\nname: outer\n")
+	writeFile(t, outer, "templates/repo/.git/HEAD", "ref: refs/heads/main\n")
+	inside := writeFile(t, outer, "templates/repo/deploy/app.yaml", "kind: Pod\n")
+	above := writeFile(t, outer, "templates/app.yaml", "kind: Pod\n")
+
+	h := &Helm{}
+	if h.MatchesPath(inside) {
+		t.Errorf("MatchesPath(%q) = true, want false", inside)
+	}
+	if !h.MatchesPath(above) {
+		t.Errorf("MatchesPath(%q) = false, want true", above)
+	}
+}
+
+func TestHelmMatchesPathCachesDirectories(t *testing.T) {
+	root := t.TempDir()
+	first := writeFile(t, root, "templates/a.yaml", "")
+	second := writeFile(t, root, "templates/b.yaml", "")
+
+	h := &Helm{}
+	if h.MatchesPath(first) {
+		t.Fatalf("MatchesPath(%q) = true without a Chart.yaml", first)
+	}
+	// The directory was looked at once and is not looked at again
+	writeFile(t, root, "Chart.yaml", "apiVersion: v2\n")
+	if h.MatchesPath(second) {
+		t.Errorf("MatchesPath(%q) = true, want the cached false", second)
+	}
+	if !(&Helm{}).MatchesPath(second) {
+		t.Errorf("MatchesPath(%q) = false with a Chart.yaml", second)
+	}
+}
+
+func TestYAMLIsSpecialComment(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"%YAML 1.2", true},
+		{"%TAG ! tag:example.com,2000:", true},
+		{"---", true},
+		{"--- ", true},
+		{"#cloud-config", true},
+		{"#cloud-config-archive", true},
+		{"#cloud-boothook", true},
+		{"#include", true},
+		{"#include-once", true},
+		{"#part-handler", true},
+		{"#upstart-job", true},
+		{"## template: jinja", true},
+		{"#!/bin/bash", true},
+		{"# cloud-config", false},
+		{"#cloud-configure", false},
+		{"# a comment", false},
+		{"key: value", false},
+		{"--- # document", false},
+	}
+
+	y := &YAML{}
+	for _, tt := range tests {
+		if got := y.IsSpecialComment(tt.line); got != tt.want {
+			t.Errorf("IsSpecialComment(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
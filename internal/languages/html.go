@@ -0,0 +1,40 @@
+package languages
+
+import "strings"
+
+// HTML is written with <!-- --> comments, which have no line comment form
+type HTML struct{}
+
+func init() {
+	Register(&HTML{})
+}
+
+func (h *HTML) Name() string {
+	return "HTML"
+}
+
+func (h *HTML) FileExtensions() []string {
+	return []string{".html", ".htm"}
+}
+
+func (h *HTML) FileNames() []string {
+	return nil
+}
+
+func (h *HTML) CommentStart() string {
+	return "<!--"
+}
+
+func (h *HTML) CommentEnd() string {
+	return "-->"
+}
+
+func (h *HTML) MultiLineCommentStart() string {
+	return "<!--"
+}
+
+// IsSpecialComment keeps the doctype first, as older browsers switch to
+// quirks mode when anything precedes it
+func (h *HTML) IsSpecialComment(line string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "<!doctype")
+}
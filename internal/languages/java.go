@@ -0,0 +1,35 @@
+package languages
+
+type Java struct{}
+
+func init() {
+	Register(&Java{})
+}
+
+func (j *Java) Name() string {
+	return "Java"
+}
+
+func (j *Java) FileExtensions() []string {
+	return []string{".java"}
+}
+
+func (j *Java) FileNames() []string {
+	return nil
+}
+
+func (j *Java) CommentStart() string {
+	return "//"
+}
+
+func (j *Java) CommentEnd() string {
+	return ""
+}
+
+func (j *Java) MultiLineCommentStart() string {
+	return "/*"
+}
+
+func (j *Java) IsSpecialComment(line string) bool {
+	return false
+}
@@ -4,8 +4,24 @@ import "strings"
 
 type JavaScript struct{}
 
+func init() {
+	Register(&JavaScript{})
+}
+
+func (js *JavaScript) Name() string {
+	return "JavaScript"
+}
+
 func (js *JavaScript) FileExtensions() []string {
-	return []string{".js", ".jsx", ".ts", ".tsx"}
+	return []string{".js", ".jsx", ".mjs", ".cjs"}
+}
+
+func (js *JavaScript) FileNames() []string {
+	return nil
+}
+
+func (js *JavaScript) Interpreters() []string {
+	return []string{"node", "nodejs", "bun"}
 }
 
 func (js *JavaScript) CommentStart() string {
@@ -21,19 +37,27 @@ func (js *JavaScript) MultiLineCommentStart() string {
 }
 
 func (js *JavaScript) IsSpecialComment(line string) bool {
+	if isShebang(line) {
+		return true
+	}
+
+	// Line-level suppressions such as @ts-ignore apply to the line that
+	// follows them, so only file-level pragmas are listed here
 	specialPrefixes := []string{
-		"@ts-ignore",
-		"@ts-nocheck",
-		"@ts-check",
-		"@flow",
-		"// eslint-",
+		"// @ts-nocheck",
+		"// @ts-check",
+		"// @flow",
+		"/* @flow",
 		"/* eslint-",
 	}
 
+	trimmed := strings.TrimSpace(line)
 	for _, prefix := range specialPrefixes {
-		if strings.HasPrefix(strings.TrimSpace(line), prefix) {
-			return true
+		if strings.HasPrefix(trimmed, prefix) {
+			// A block comment has to close on the same line, or the header
+			// would end up inside it
+			return !strings.HasPrefix(prefix, "/*") || strings.Contains(trimmed, "*/")
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,43 @@
+package languages
+
+// Kotlin covers Kotlin sources and scripts, including Gradle build scripts
+type Kotlin struct{}
+
+func init() {
+	Register(&Kotlin{})
+}
+
+func (k *Kotlin) Name() string {
+	return "Kotlin"
+}
+
+func (k *Kotlin) FileExtensions() []string {
+	return []string{".kt", ".kts"}
+}
+
+func (k *Kotlin) FileNames() []string {
+	return nil
+}
+
+func (k *Kotlin) Interpreters() []string {
+	return []string{"kotlin", "kscript"}
+}
+
+func (k *Kotlin) CommentStart() string {
+	return "//"
+}
+
+func (k *Kotlin) CommentEnd() string {
+	return ""
+}
+
+func (k *Kotlin) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment keeps the shebang of a script first. File annotations
+// such as @file:JvmName only have to precede the package directive, so the
+// header can go above them.
+func (k *Kotlin) IsSpecialComment(line string) bool {
+	return isShebang(line)
+}
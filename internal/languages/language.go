@@ -1,14 +1,58 @@
 package languages
 
+import "strings"
+
+// Language describes how codemap recognises and comments a kind of source file.
+// Implementations register themselves with Register, so adding a language is
+// a matter of adding one file to this package.
 type Language interface {
-	// FileExtensions returns the file extensions this language handles
+	// Name returns the language name written into the lang field
+	Name() string
+	// FileExtensions returns the lowercase file extensions this language handles
 	FileExtensions() []string
-	// CommentStart returns the string that starts a single-line comment
+	// FileNames returns file name patterns this language handles, in
+	// path.Match syntax, such as "Dockerfile" or "Dockerfile.*"
+	FileNames() []string
+	// CommentStart returns the string that starts a single-line comment, or
+	// a block comment for languages without line comments
 	CommentStart() string
-	// CommentEnd returns the string that ends a multi-line comment (if applicable)
+	// CommentEnd returns the string that ends a multi-line comment (if
+	// applicable). Languages that set it get their header as a block comment
+	// closed on the same line.
 	CommentEnd() string
 	// MultiLineCommentStart returns the string that starts a multi-line comment
 	MultiLineCommentStart() string
-	// IsSpecialComment returns true if this is a special comment that shouldn't be removed
+	// IsSpecialComment returns true if this is a special leading line, such as a
+	// shebang or a parser directive, that has to stay above the codemap header
 	IsSpecialComment(line string) bool
-}
\ No newline at end of file
+}
+
+// Scripted is implemented by languages whose extensionless scripts can be
+// recognised by the interpreter named on their shebang line
+type Scripted interface {
+	// Interpreters returns interpreter names such as "python3". Version
+	// suffixes are ignored when matching, so "python" also matches "python3.12".
+	Interpreters() []string
+}
+
+// Located is implemented by languages recognised by where a file sits rather
+// than by its name, such as the templates of a Helm chart. ForPath asks them
+// before looking at names and extensions.
+type Located interface {
+	// MatchesPath reports whether the file at path is in this language
+	MatchesPath(path string) bool
+}
+
+// Placed is implemented by languages whose leading lines cannot be judged one
+// at a time, such as the front matter block of a Markdown file. It replaces
+// IsSpecialComment for placing the header.
+type Placed interface {
+	// HeaderLine returns the index of the line the header is inserted before.
+	// lines have their terminators removed.
+	HeaderLine(lines []string) int
+}
+
+// isShebang reports whether line is an interpreter line such as "#!/bin/sh"
+func isShebang(line string) bool {
+	return strings.HasPrefix(line, "#!")
+}
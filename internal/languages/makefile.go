@@ -0,0 +1,39 @@
+package languages
+
+type Makefile struct{}
+
+func init() {
+	Register(&Makefile{})
+}
+
+func (m *Makefile) Name() string {
+	return "Makefile"
+}
+
+func (m *Makefile) FileExtensions() []string {
+	return []string{".mk", ".make"}
+}
+
+func (m *Makefile) FileNames() []string {
+	return []string{"Makefile", "makefile", "GNUmakefile"}
+}
+
+func (m *Makefile) Interpreters() []string {
+	return []string{"make"}
+}
+
+func (m *Makefile) CommentStart() string {
+	return "#"
+}
+
+func (m *Makefile) CommentEnd() string {
+	return ""
+}
+
+func (m *Makefile) MultiLineCommentStart() string {
+	return "#"
+}
+
+func (m *Makefile) IsSpecialComment(line string) bool {
+	return isShebang(line)
+}
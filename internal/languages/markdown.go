@@ -0,0 +1,43 @@
+package languages
+
+import "strings"
+
+// Markdown takes HTML comments, which renderers leave out of the output
+type Markdown struct {
+	HTML
+}
+
+func init() {
+	Register(&Markdown{})
+}
+
+func (m *Markdown) Name() string {
+	return "Markdown"
+}
+
+func (m *Markdown) FileExtensions() []string {
+	return []string{".md", ".markdown"}
+}
+
+func (m *Markdown) IsSpecialComment(line string) bool {
+	return false
+}
+
+// HeaderLine puts the header below a YAML (---) or TOML (+++) front matter
+// block, which static site generators only recognise on the first line
+func (m *Markdown) HeaderLine(lines []string) int {
+	if len(lines) == 0 {
+		return 0
+	}
+	open := strings.TrimSpace(lines[0])
+	if open != "---" && open != "+++" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == open || (open == "---" && line == "...") {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package languages
+
+import "strings"
+
+type PHP struct{}
+
+func init() {
+	Register(&PHP{})
+}
+
+func (p *PHP) Name() string {
+	return "PHP"
+}
+
+func (p *PHP) FileExtensions() []string {
+	return []string{".php"}
+}
+
+func (p *PHP) FileNames() []string {
+	return nil
+}
+
+func (p *PHP) Interpreters() []string {
+	return []string{"php"}
+}
+
+func (p *PHP) CommentStart() string {
+	return "//"
+}
+
+func (p *PHP) CommentEnd() string {
+	return ""
+}
+
+func (p *PHP) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment keeps the shebang and the <?php opening tag above the
+// header, which has to be inside PHP code to be a comment. A line that closes
+// the tag again is not special, as the header would land in output text.
+func (p *PHP) IsSpecialComment(line string) bool {
+	if isShebang(line) {
+		return true
+	}
+	return IsPHPOpenTag(line)
+}
+
+// IsPHPOpenTag reports whether line opens PHP code that is still open at the
+// end of the line
+func IsPHPOpenTag(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(strings.ToLower(line), "<?php") && !strings.Contains(line, "?>")
+}
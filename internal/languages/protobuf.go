@@ -0,0 +1,37 @@
+package languages
+
+type Protobuf struct{}
+
+func init() {
+	Register(&Protobuf{})
+}
+
+func (p *Protobuf) Name() string {
+	return "Protobuf"
+}
+
+func (p *Protobuf) FileExtensions() []string {
+	return []string{".proto"}
+}
+
+func (p *Protobuf) FileNames() []string {
+	return nil
+}
+
+func (p *Protobuf) CommentStart() string {
+	return "//"
+}
+
+func (p *Protobuf) CommentEnd() string {
+	return ""
+}
+
+func (p *Protobuf) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment always returns false. The syntax or edition statement has
+// to be the first statement of the file, but comments may precede it.
+func (p *Protobuf) IsSpecialComment(line string) bool {
+	return false
+}
@@ -1,13 +1,35 @@
 package languages
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
+
+// encodingCookie matches a PEP 263 source encoding declaration
+var encodingCookie = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=][ \t]*[-\w.]+`)
 
 type Python struct{}
 
+func init() {
+	Register(&Python{})
+}
+
+func (p *Python) Name() string {
+	return "Python"
+}
+
 func (p *Python) FileExtensions() []string {
 	return []string{".py"}
 }
 
+func (p *Python) FileNames() []string {
+	return nil
+}
+
+func (p *Python) Interpreters() []string {
+	return []string{"python", "pypy"}
+}
+
 func (p *Python) CommentStart() string {
 	return "#"
 }
@@ -21,6 +43,10 @@ func (p *Python) MultiLineCommentStart() string {
 }
 
 func (p *Python) IsSpecialComment(line string) bool {
+	if isShebang(line) || encodingCookie.MatchString(line) {
+		return true
+	}
+
 	specialPrefixes := []string{
 		"# type:",
 		"# noqa:",
@@ -34,4 +60,23 @@ func (p *Python) IsSpecialComment(line string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// HeaderLine puts the header below the leading special lines. PEP 263 also
+// reads an encoding cookie from the second line when the first is blank or
+// another comment, such as a copyright notice, so the header goes below that
+// cookie too, or Python would no longer see it. After a docstring opener or
+// code the cookie is ignored, and the header goes on the first line.
+func (p *Python) HeaderLine(lines []string) int {
+	i := 0
+	for i < len(lines) && p.IsSpecialComment(lines[i]) {
+		i++
+	}
+	if i < 2 && len(lines) > 1 && encodingCookie.MatchString(lines[1]) {
+		if first := strings.TrimSpace(lines[0]); first == "" || strings.HasPrefix(first, "#") {
+			return 2
+		}
+		return 0
+	}
+	return i
+}
@@ -0,0 +1,147 @@
+package languages
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   []Language
+)
+
+// Register adds a language to the registry. Each language registers itself
+// from an init function in its own file.
+func Register(lang Language) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, lang)
+}
+
+// All returns the registered languages sorted by name
+func All() []Language {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	all := append([]Language(nil), registry...)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// ForPath returns the language of the file at path. Located languages come
+// first, then file name patterns such as "Dockerfile.*" take precedence over
+// extensions, and files without an extension are recognised by their shebang
+// line.
+func ForPath(path string) (Language, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, lang := range registry {
+		if located, ok := lang.(Located); ok && located.MatchesPath(path) {
+			return lang, true
+		}
+	}
+
+	base := filepath.Base(path)
+	for _, lang := range registry {
+		for _, pattern := range lang.FileNames() {
+			if ok, _ := filepath.Match(pattern, base); ok {
+				return lang, true
+			}
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return forInterpreter(shebangInterpreter(path))
+	}
+	for _, lang := range registry {
+		for _, e := range lang.FileExtensions() {
+			if ext == e {
+				return lang, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// forInterpreter returns the language that runs scripts with interpreter
+func forInterpreter(interpreter string) (Language, bool) {
+	if interpreter == "" {
+		return nil, false
+	}
+	unversioned := strings.TrimRight(interpreter, "0123456789.")
+	for _, lang := range registry {
+		scripted, ok := lang.(Scripted)
+		if !ok {
+			continue
+		}
+		for _, name := range scripted.Interpreters() {
+			if interpreter == name || unversioned == name {
+				return lang, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// shebangInterpreter returns the base name of the interpreter on the shebang
+// line of the file at path, looking through /usr/bin/env and its options
+func shebangInterpreter(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	buf := make([]byte, 256)
+	n, _ := io.ReadFull(f, buf)
+	line, _, _ := strings.Cut(string(buf[:n]), "\n")
+	if !isShebang(line) {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter != "env" {
+		return interpreter
+	}
+	for _, arg := range fields[1:] {
+		if strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+			continue
+		}
+		return filepath.Base(arg)
+	}
+	return ""
+}
+
+// ByName returns the registered language with the given name, ignoring case
+func ByName(name string) (Language, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, lang := range registry {
+		if strings.EqualFold(lang.Name(), name) {
+			return lang, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the registered languages, sorted
+func Names() []string {
+	var names []string
+	for _, lang := range All() {
+		names = append(names, lang.Name())
+	}
+	return names
+}
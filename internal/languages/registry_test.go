@@ -0,0 +1,184 @@
+package languages
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name under dir, creating parent directories,
+// and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// languageOf returns the name of the language ForPath finds for path, or ""
+func languageOf(path string) string {
+	lang, ok := ForPath(path)
+	if !ok {
+		return ""
+	}
+	return lang.Name()
+}
+
+func TestForPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"main.go", "", "Go"},
+		{"app.py", "", "Python"},
+		{"index.js", "", "JavaScript"},
+		{"module.mjs", "", "JavaScript"},
+		{"config.cjs", "", "JavaScript"},
+		{"index.ts", "", "TypeScript"},
+		{"module.mts", "", "TypeScript"},
+		{"UPPER.PY", "", "Python"},
+		{"a.c", "", "C"},
+		{"a.h", "", "C"},
+		{"a.hpp", "", "C++"},
+
+		// File name patterns take precedence over extensions
+		{"Dockerfile", "", "Dockerfile"},
+		{"Dockerfile.dev", "", "Dockerfile"},
+		{"Dockerfile.prod.yaml", "", "Dockerfile"},
+		{"Containerfile", "", "Dockerfile"},
+		{"Jenkinsfile", "", "Groovy"},
+		{"Makefile", "", "Makefile"},
+		{"GNUmakefile", "", "Makefile"},
+		{"Rakefile", "", "Ruby"},
+		{"Gemfile", "", "Ruby"},
+
+		// Extensionless files without a shebang are not source files
+		{"LICENSE", "Permission is hereby granted\n", ""},
+		{"Procfile", "web: bin/server\n", ""},
+		{"CODEOWNERS", "* @team\n", ""},
+		{"README", "", ""},
+		{"notes.txt", "", ""},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, dir, tt.name, tt.content)
+			if got := languageOf(path); got != tt.want {
+				t.Errorf("ForPath(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestByName(t *testing.T) {
+	for _, name := range []string{"go", "Go", "PYTHON", "c++", "Dockerfile"} {
+		if _, ok := ByName(name); !ok {
+			t.Errorf("ByName(%q) found nothing", name)
+		}
+	}
+	if lang, ok := ByName("cobol"); ok {
+		t.Errorf("ByName(\"cobol\") = %s", lang.Name())
+	}
+}
+
+func TestNamesAreSorted(t *testing.T) {
+	names := Names()
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Errorf("Names() not sorted or repeated at %q, %q", names[i-1], names[i])
+		}
+	}
+}
+
+func TestExtensionsAreUnique(t *testing.T) {
+	seen := map[string]string{}
+	for _, lang := range All() {
+		for _, ext := range lang.FileExtensions() {
+			if other, ok := seen[ext]; ok {
+				t.Errorf("%s is claimed by %s and %s", ext, other, lang.Name())
+			}
+			seen[ext] = lang.Name()
+		}
+	}
+}
+
+func TestForPathShebang(t *testing.T) {
+	tests := []struct {
+		name    string
+		shebang string
+		want    string
+	}{
+		{"absolute interpreter", "#!/bin/sh", "Shell"},
+		{"bash", "#!/bin/bash -e", "Shell"},
+		{"env", "#!/usr/bin/env bash", "Shell"},
+		{"env split string", "#!/usr/bin/env -S python3 -u", "Python"},
+		{"env with assignment", "#!/usr/bin/env PYTHONDONTWRITEBYTECODE=1 python3", "Python"},
+		{"env with several options", "#!/usr/bin/env -i -S ruby -w", "Ruby"},
+		{"versioned interpreter", "#!/usr/bin/python3.11", "Python"},
+		{"versioned env interpreter", "#!/usr/bin/env python3.12", "Python"},
+		{"pypy", "#!/usr/bin/env pypy3", "Python"},
+		{"node", "#!/usr/bin/env node", "JavaScript"},
+		{"deno", "#!/usr/bin/env -S deno run --allow-net", "TypeScript"},
+		{"make", "#!/usr/bin/make -f", "Makefile"},
+		{"space after bang", "#! /bin/zsh", "Shell"},
+		{"crlf", "#!/bin/sh\r", "Shell"},
+		{"unknown interpreter", "#!/usr/bin/env perl", ""},
+		{"env without interpreter", "#!/usr/bin/env", ""},
+		{"empty shebang", "#!", ""},
+		{"not on the first line", "\n#!/bin/sh", ""},
+		{"comment", "# /bin/sh", ""},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, dir, filepath.Join("bin", string(rune('a'+i))), tt.shebang+"\necho\n")
+			if got := languageOf(path); got != tt.want {
+				t.Errorf("ForPath() with %q = %q, want %q", tt.shebang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForPathShebangIgnoredWithExtension(t *testing.T) {
+	// An extension decides the language even when the shebang disagrees
+	path := writeFile(t, t.TempDir(), "tool.py", "#!/bin/sh\n")
+	if got := languageOf(path); got != "Python" {
+		t.Errorf("ForPath() = %q, want Python", got)
+	}
+}
+
+func TestShebangInterpreter(t *testing.T) {
+	tests := map[string]string{
+		"#!/bin/sh":                      "sh",
+		"#!/usr/bin/env -S python3 -u":   "python3",
+		"#!/usr/bin/env A=1 B=2 node":    "node",
+		"#!/usr/local/bin/python3.11 -O": "python3.11",
+		"#!/usr/bin/env":                 "",
+		"#!/usr/bin/env -i":              "",
+		"no shebang":                     "",
+		// Only the first 256 bytes are read
+		"#!/usr/bin/env" + strings.Repeat(" ", 300) + "bash": "",
+	}
+
+	dir := t.TempDir()
+	i := 0
+	for line, want := range tests {
+		i++
+		path := writeFile(t, dir, string(rune('a'+i)), line+"\n")
+		if got := shebangInterpreter(path); got != want {
+			t.Errorf("shebangInterpreter(%q) = %q, want %q", line, got, want)
+		}
+	}
+	if got := shebangInterpreter(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("shebangInterpreter() of a missing file = %q", got)
+	}
+}
@@ -0,0 +1,50 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rubyMagicComment matches the magic comments Ruby reads from the top of a
+// file, such as "# frozen_string_literal: true" and "# -*- coding: utf-8 -*-"
+var rubyMagicComment = regexp.MustCompile(`(?i)^#.*\b(frozen[-_]string[-_]literal|encoding|coding|warn[-_]indent|shareable[-_]constant[-_]value)\s*:`)
+
+type Ruby struct{}
+
+func init() {
+	Register(&Ruby{})
+}
+
+func (r *Ruby) Name() string {
+	return "Ruby"
+}
+
+func (r *Ruby) FileExtensions() []string {
+	return []string{".rb", ".rake", ".gemspec"}
+}
+
+func (r *Ruby) FileNames() []string {
+	return []string{"Rakefile", "Gemfile"}
+}
+
+func (r *Ruby) Interpreters() []string {
+	return []string{"ruby"}
+}
+
+func (r *Ruby) CommentStart() string {
+	return "#"
+}
+
+func (r *Ruby) CommentEnd() string {
+	return ""
+}
+
+func (r *Ruby) MultiLineCommentStart() string {
+	return "=begin"
+}
+
+// IsSpecialComment keeps the shebang and magic comments above the header, as
+// Ruby only reads some magic comments from the first lines of a file
+func (r *Ruby) IsSpecialComment(line string) bool {
+	return isShebang(line) || rubyMagicComment.MatchString(strings.TrimSpace(line))
+}
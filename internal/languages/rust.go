@@ -0,0 +1,42 @@
+package languages
+
+import "strings"
+
+type Rust struct{}
+
+func init() {
+	Register(&Rust{})
+}
+
+func (r *Rust) Name() string {
+	return "Rust"
+}
+
+func (r *Rust) FileExtensions() []string {
+	return []string{".rs"}
+}
+
+func (r *Rust) FileNames() []string {
+	return nil
+}
+
+func (r *Rust) CommentStart() string {
+	return "//"
+}
+
+func (r *Rust) CommentEnd() string {
+	return ""
+}
+
+func (r *Rust) MultiLineCommentStart() string {
+	return "/*"
+}
+
+// IsSpecialComment only keeps a shebang above the header. Inner attributes
+// (#![...]) and inner doc comments (//!) must come before the first item of a
+// module, and a plain comment is not an item, so a header on the first line
+// leaves them valid. An inner attribute starts with "#!" too, but it is not a
+// shebang.
+func (r *Rust) IsSpecialComment(line string) bool {
+	return isShebang(line) && !strings.HasPrefix(strings.TrimSpace(line[2:]), "[")
+}
@@ -0,0 +1,19 @@
+package languages
+
+// SCSS also has // comments, but the header uses the /* */ form it shares
+// with CSS
+type SCSS struct {
+	CSS
+}
+
+func init() {
+	Register(&SCSS{})
+}
+
+func (s *SCSS) Name() string {
+	return "SCSS"
+}
+
+func (s *SCSS) FileExtensions() []string {
+	return []string{".scss"}
+}
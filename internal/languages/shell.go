@@ -0,0 +1,39 @@
+package languages
+
+type Shell struct{}
+
+func init() {
+	Register(&Shell{})
+}
+
+func (s *Shell) Name() string {
+	return "Shell"
+}
+
+func (s *Shell) FileExtensions() []string {
+	return []string{".sh", ".bash", ".zsh"}
+}
+
+func (s *Shell) FileNames() []string {
+	return nil
+}
+
+func (s *Shell) Interpreters() []string {
+	return []string{"sh", "bash", "zsh", "dash", "ksh", "ash"}
+}
+
+func (s *Shell) CommentStart() string {
+	return "#"
+}
+
+func (s *Shell) CommentEnd() string {
+	return ""
+}
+
+func (s *Shell) MultiLineCommentStart() string {
+	return ""
+}
+
+func (s *Shell) IsSpecialComment(line string) bool {
+	return isShebang(line)
+}
@@ -0,0 +1,35 @@
+package languages
+
+type SQL struct{}
+
+func init() {
+	Register(&SQL{})
+}
+
+func (s *SQL) Name() string {
+	return "SQL"
+}
+
+func (s *SQL) FileExtensions() []string {
+	return []string{".sql"}
+}
+
+func (s *SQL) FileNames() []string {
+	return nil
+}
+
+func (s *SQL) CommentStart() string {
+	return "--"
+}
+
+func (s *SQL) CommentEnd() string {
+	return ""
+}
+
+func (s *SQL) MultiLineCommentStart() string {
+	return "/*"
+}
+
+func (s *SQL) IsSpecialComment(line string) bool {
+	return false
+}
@@ -0,0 +1,23 @@
+package languages
+
+// Svelte components accept comments at the top level, so the header goes on
+// the first line, outside <script> and <style>
+type Svelte struct {
+	HTML
+}
+
+func init() {
+	Register(&Svelte{})
+}
+
+func (s *Svelte) Name() string {
+	return "Svelte"
+}
+
+func (s *Svelte) FileExtensions() []string {
+	return []string{".svelte"}
+}
+
+func (s *Svelte) IsSpecialComment(line string) bool {
+	return false
+}
@@ -0,0 +1,35 @@
+package languages
+
+type Terraform struct{}
+
+func init() {
+	Register(&Terraform{})
+}
+
+func (t *Terraform) Name() string {
+	return "Terraform"
+}
+
+func (t *Terraform) FileExtensions() []string {
+	return []string{".tf", ".tfvars"}
+}
+
+func (t *Terraform) FileNames() []string {
+	return nil
+}
+
+func (t *Terraform) CommentStart() string {
+	return "#"
+}
+
+func (t *Terraform) CommentEnd() string {
+	return ""
+}
+
+func (t *Terraform) MultiLineCommentStart() string {
+	return "/*"
+}
+
+func (t *Terraform) IsSpecialComment(line string) bool {
+	return false
+}
@@ -0,0 +1,22 @@
+package languages
+
+// TypeScript shares its comment syntax and pragmas with JavaScript
+type TypeScript struct {
+	JavaScript
+}
+
+func init() {
+	Register(&TypeScript{})
+}
+
+func (ts *TypeScript) Name() string {
+	return "TypeScript"
+}
+
+func (ts *TypeScript) FileExtensions() []string {
+	return []string{".ts", ".tsx", ".mts", ".cts"}
+}
+
+func (ts *TypeScript) Interpreters() []string {
+	return []string{"deno", "ts-node", "tsx"}
+}
@@ -0,0 +1,23 @@
+package languages
+
+// Vue single-file components accept comments between their top-level blocks,
+// so the header goes on the first line, outside <template> and <script>
+type Vue struct {
+	HTML
+}
+
+func init() {
+	Register(&Vue{})
+}
+
+func (v *Vue) Name() string {
+	return "Vue"
+}
+
+func (v *Vue) FileExtensions() []string {
+	return []string{".vue"}
+}
+
+func (v *Vue) IsSpecialComment(line string) bool {
+	return false
+}
@@ -0,0 +1,26 @@
+package languages
+
+import "strings"
+
+// XML shares its comment syntax with HTML
+type XML struct {
+	HTML
+}
+
+func init() {
+	Register(&XML{})
+}
+
+func (x *XML) Name() string {
+	return "XML"
+}
+
+func (x *XML) FileExtensions() []string {
+	return []string{".xml", ".xsd", ".xsl", ".xslt"}
+}
+
+// IsSpecialComment keeps the XML declaration first, where the specification
+// requires it to be
+func (x *XML) IsSpecialComment(line string) bool {
+	return strings.HasPrefix(line, "<?xml")
+}
@@ -0,0 +1,63 @@
+package languages
+
+import "strings"
+
+// YAML covers Kubernetes manifests, GitHub Actions workflows and other YAML
+// files. Helm chart templates have a language of their own.
+type YAML struct{}
+
+func init() {
+	Register(&YAML{})
+}
+
+func (y *YAML) Name() string {
+	return "YAML"
+}
+
+func (y *YAML) FileExtensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+func (y *YAML) FileNames() []string {
+	return nil
+}
+
+func (y *YAML) CommentStart() string {
+	return "#"
+}
+
+func (y *YAML) CommentEnd() string {
+	return ""
+}
+
+func (y *YAML) MultiLineCommentStart() string {
+	return ""
+}
+
+// cloudInitMarkers are the first lines that tell cloud-init what kind of
+// user-data a file holds. A "## template: jinja" line may come before them.
+var cloudInitMarkers = []string{
+	"#cloud-config",
+	"#cloud-config-archive",
+	"#cloud-boothook",
+	"#include",
+	"#include-once",
+	"#part-handler",
+	"#upstart-job",
+}
+
+// IsSpecialComment keeps %YAML and %TAG directives, the first document marker
+// and cloud-init user-data markers above the header, so the file still opens
+// with them
+func (y *YAML) IsSpecialComment(line string) bool {
+	line = strings.TrimRight(line, " \t")
+	if strings.HasPrefix(line, "%") || line == "---" || isShebang(line) || strings.HasPrefix(line, "## template:") {
+		return true
+	}
+	for _, marker := range cloudInitMarkers {
+		if line == marker {
+			return true
+		}
+	}
+	return false
+}
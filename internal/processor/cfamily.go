@@ -0,0 +1,194 @@
+package processor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// cTargets finds the build targets C and C++ files belong to, from CMake
+// files and compilation databases
+type cTargets struct {
+	mu        sync.Mutex
+	cmake     map[string][]cmakeTarget     // CMakeLists.txt -> targets it defines
+	databases map[string]map[string]string // compile_commands.json -> source file -> target
+}
+
+// cmakeTarget is a library or executable and its absolute source paths
+type cmakeTarget struct {
+	name    string
+	sources map[string]bool
+}
+
+func newCTargets() *cTargets {
+	return &cTargets{
+		cmake:     make(map[string][]cmakeTarget),
+		databases: make(map[string]map[string]string),
+	}
+}
+
+// resolveC names the package after the build target that compiles the file,
+// falling back to the directory name
+func resolveC(p *Processor, f *sourceFile) packageInfo {
+	return packageInfo{Package: p.cTargets.find(f.abs, p.paths.gitRoot)}
+}
+
+// find returns the target owning the file at absPath, looking no higher than
+// root. The nearest CMakeLists.txt decides if it lists the file or defines
+// a single target; those further up only if they list the file. Otherwise
+// the target is read from the object file name in the nearest
+// compile_commands.json, which CMake writes as CMakeFiles/<target>.dir/...
+func (c *cTargets) find(absPath, root string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	nearest := true
+	for dir := filepath.Dir(absPath); isWithin(root, dir); dir = filepath.Dir(dir) {
+		file := filepath.Join(dir, "CMakeLists.txt")
+		if fileExists(file) {
+			targets, ok := c.cmake[file]
+			if !ok {
+				targets = readCMakeTargets(file)
+				c.cmake[file] = targets
+			}
+			for _, t := range targets {
+				if t.sources[absPath] {
+					return t.name
+				}
+			}
+			if nearest && len(targets) == 1 {
+				return targets[0].name
+			}
+			nearest = false
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	for dir := filepath.Dir(absPath); isWithin(root, dir); dir = filepath.Dir(dir) {
+		for _, name := range []string{"compile_commands.json", "build/compile_commands.json"} {
+			file := filepath.Join(dir, filepath.FromSlash(name))
+			if !fileExists(file) {
+				continue
+			}
+			db, ok := c.databases[file]
+			if !ok {
+				db = readCompileCommands(file)
+				c.databases[file] = db
+			}
+			if target := db[absPath]; target != "" {
+				return target
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return ""
+}
+
+var (
+	// cmakeCommand matches the commands that define targets or add sources to them
+	cmakeCommand = regexp.MustCompile(`(?is)\b(add_library|add_executable|target_sources)\s*\(([^)]*)\)`)
+	// cmakeComment matches a # comment up to the end of the line
+	cmakeComment = regexp.MustCompile(`#[^\n]*`)
+)
+
+// cmakeKeywords are arguments of add_library, add_executable and
+// target_sources that are not source files
+var cmakeKeywords = map[string]bool{
+	"STATIC": true, "SHARED": true, "MODULE": true, "OBJECT": true,
+	"INTERFACE": true, "IMPORTED": true, "GLOBAL": true, "UNKNOWN": true,
+	"EXCLUDE_FROM_ALL": true, "WIN32": true, "MACOSX_BUNDLE": true,
+	"PUBLIC": true, "PRIVATE": true, "FILE_SET": true, "TYPE": true,
+	"HEADERS": true, "CXX_MODULES": true, "BASE_DIRS": true, "FILES": true,
+}
+
+// readCMakeTargets reads the targets defined by a CMakeLists.txt, with the
+// sources listed for them. Sources given through variables or generator
+// expressions are not expanded.
+func readCMakeTargets(file string) []cmakeTarget {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	dir := filepath.Dir(file)
+
+	var targets []cmakeTarget
+	index := make(map[string]int)
+	for _, m := range cmakeCommand.FindAllStringSubmatch(cmakeComment.ReplaceAllString(string(data), ""), -1) {
+		args := strings.Fields(m[2])
+		if len(args) == 0 {
+			continue
+		}
+		name := strings.Trim(args[0], `"`)
+		if strings.EqualFold(m[1], "target_sources") {
+			if _, ok := index[name]; !ok {
+				continue
+			}
+		} else if len(args) > 1 && strings.EqualFold(args[1], "ALIAS") {
+			continue
+		} else if _, ok := index[name]; !ok {
+			index[name] = len(targets)
+			targets = append(targets, cmakeTarget{name: name, sources: make(map[string]bool)})
+		}
+
+		t := targets[index[name]]
+		for _, arg := range args[1:] {
+			arg = strings.Trim(arg, `"`)
+			if cmakeKeywords[strings.ToUpper(arg)] || strings.Contains(arg, "$<") {
+				continue
+			}
+			for _, v := range []string{"${CMAKE_CURRENT_SOURCE_DIR}/", "${CMAKE_CURRENT_LIST_DIR}/"} {
+				arg = strings.TrimPrefix(arg, v)
+			}
+			if strings.Contains(arg, "${") {
+				continue
+			}
+			if !filepath.IsAbs(arg) {
+				arg = filepath.Join(dir, filepath.FromSlash(arg))
+			}
+			t.sources[filepath.Clean(arg)] = true
+		}
+	}
+	return targets
+}
+
+// cmakeObjectDir matches the object directory CMake gives each target
+var cmakeObjectDir = regexp.MustCompile(`CMakeFiles/([^/]+)\.dir/`)
+
+// readCompileCommands maps the sources in a compilation database to the
+// targets their object files belong to
+func readCompileCommands(file string) map[string]string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var entries []struct {
+		Directory string   `json:"directory"`
+		File      string   `json:"file"`
+		Output    string   `json:"output"`
+		Command   string   `json:"command"`
+		Arguments []string `json:"arguments"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil
+	}
+
+	db := make(map[string]string, len(entries))
+	for _, e := range entries {
+		source := e.File
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(e.Directory, source)
+		}
+		command := e.Output + " " + e.Command + " " + strings.Join(e.Arguments, " ")
+		if m := cmakeObjectDir.FindStringSubmatch(filepath.ToSlash(command)); m != nil {
+			db[filepath.Clean(source)] = m[1]
+		}
+	}
+	return db
+}
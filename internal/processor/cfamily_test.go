@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReadCMakeTargets(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"CMakeLists.txt": `cmake_minimum_required(VERSION 3.20)
+project(acme C CXX)
+
+# add_library(old old.c)
+add_library(core STATIC
+  src/core.c
+  "src/quoted.c"
+  ${CMAKE_CURRENT_SOURCE_DIR}/src/current.c
+  ${GENERATED_SOURCES}
+  $<$<PLATFORM_ID:Linux>:src/linux.c>
+)
+add_library(acme::core ALIAS core)
+ADD_EXECUTABLE(app WIN32 main.cpp)
+target_sources(core PRIVATE src/extra.c PUBLIC FILE_SET HEADERS FILES include/core.h)
+target_sources(unknown PRIVATE src/unknown.c)
+`})
+
+	got := map[string][]string{}
+	for _, target := range readCMakeTargets(filepath.Join(dir, "CMakeLists.txt")) {
+		sources := []string{}
+		for source := range target.sources {
+			sources = append(sources, relSlash(dir, source))
+		}
+		got[target.name] = sources
+	}
+	for _, sources := range got {
+		sort.Strings(sources)
+	}
+	want := map[string][]string{
+		"core": {"include/core.h", "src/core.c", "src/current.c", "src/extra.c", "src/quoted.c"},
+		"app":  {"main.cpp"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readCMakeTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestReadCompileCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"compile_commands.json": `[
+  {"directory": "` + filepath.ToSlash(dir) + `/build", "file": "../src/a.c",
+   "command": "cc -o CMakeFiles/alpha.dir/src/a.c.o -c ../src/a.c"},
+  {"directory": "` + filepath.ToSlash(dir) + `/build", "file": "` + filepath.ToSlash(dir) + `/src/b.c",
+   "arguments": ["cc", "-c", "../src/b.c"], "output": "lib/CMakeFiles/beta.dir/b.c.o"},
+  {"directory": "` + filepath.ToSlash(dir) + `", "file": "src/c.c", "command": "cc -c src/c.c -o c.o"}
+]`})
+
+	got := map[string]string{}
+	for source, target := range readCompileCommands(filepath.Join(dir, "compile_commands.json")) {
+		got[relSlash(dir, source)] = target
+	}
+	want := map[string]string{"src/a.c": "alpha", "src/b.c": "beta"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readCompileCommands() = %v, want %v", got, want)
+	}
+}
+
+func TestCTargetsFind(t *testing.T) {
+	outer := t.TempDir()
+	root := filepath.Join(outer, "repo")
+	writeFiles(t, outer, map[string]string{
+		// Outside the repository, and must never be read
+		"CMakeLists.txt":        "add_library(outside outside.c)\n",
+		"compile_commands.json": `[{"directory": "/", "file": "` + filepath.ToSlash(root) + `/tools/gen.c", "command": "cc -o CMakeFiles/outside.dir/gen.c.o"}]`,
+
+		"repo/CMakeLists.txt":             "add_library(lib src/lib.c)\nadd_executable(cli src/cli.c)\n",
+		"repo/src/lib.c":                  "",
+		"repo/src/unlisted.c":             "",
+		"repo/app/CMakeLists.txt":         "add_executable(app main.c)\n",
+		"repo/app/main.c":                 "",
+		"repo/app/util/helper.c":          "",
+		"repo/app/plugins/CMakeLists.txt": "add_library(a a.c)\nadd_library(b b.c)\n",
+		"repo/app/plugins/other.c":        "",
+		"repo/vendor/CMakeLists.txt":      "add_library(zlib zlib.c)\nadd_library(png png.c)\n",
+		"repo/vendor/png.c":               "",
+		"repo/vendor/extra.c":             "",
+		"repo/tools/gen.c":                "",
+		"repo/build/compile_commands.json": `[{"directory": "` + filepath.ToSlash(root) + `/build", "file": "../vendor/extra.c",
+		  "command": "cc -o vendor/CMakeFiles/zlib.dir/extra.c.o -c ../vendor/extra.c"}]`,
+	})
+
+	tests := []struct {
+		path, want string
+	}{
+		{"src/lib.c", "lib"},
+		{"app/main.c", "app"},
+		{"vendor/png.c", "png"},
+		{"src/unlisted.c", ""},
+		// The nearest CMakeLists.txt claims unlisted files if it has one target
+		{"app/util/helper.c", "app"},
+		// but one further up does not
+		{"app/plugins/other.c", ""},
+		{"vendor/extra.c", "zlib"},
+		// Nothing outside the repository is read
+		{"tools/gen.c", ""},
+	}
+
+	c := newCTargets()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := c.find(filepath.Join(root, filepath.FromSlash(tt.path)), root); got != tt.want {
+				t.Errorf("find() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// FindingKind classifies a problem found by Check
+type FindingKind string
+
+const (
+	// FindingMissing is a file without an annotation
+	FindingMissing FindingKind = "missing"
+	// FindingStale is an annotation whose fields no longer match the file
+	FindingStale FindingKind = "stale"
+	// FindingMalformed is an annotation that cannot be parsed
+	FindingMalformed FindingKind = "malformed"
+)
+
+// Finding is a file whose annotation is missing, stale or malformed
+type Finding struct {
+	// Path is the absolute path of the file
+	Path string
+	// Line is the 1-based line of the annotation, or where it belongs
+	Line int
+	Kind FindingKind
+	// Message describes the problem
+	Message string
+}
+
+// Check reports every supported file whose annotation is not what Process
+// would write, without changing any file. Findings are in walk order.
+func (p *Processor) Check() ([]Finding, error) {
+	changes, err := p.PlanApply()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, change := range changes {
+		if f, ok := p.finding(change); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// finding turns a planned change into a finding, if the change shows a problem
+func (p *Processor) finding(change *annotator.Change) (Finding, bool) {
+	f := Finding{Path: change.Path, Line: change.Line}
+
+	switch {
+	case change.Result == annotator.Added:
+		f.Kind = FindingMissing
+		f.Message = "file has no codemap annotation"
+	case change.Result != annotator.Refreshed:
+		return Finding{}, false
+	case change.ParseErr != nil:
+		f.Kind = FindingMalformed
+		f.Message = change.ParseErr.Error()
+	default:
+		f.Kind = FindingStale
+		mismatches := p.fileInfo(change.Path).Mismatches(*change.Existing)
+		f.Message = fmt.Sprintf("annotation is stale: %s", strings.Join(mismatches, "; "))
+	}
+	return f, true
+}
@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestProcessor returns a Processor for dir that writes the given fields
+func newTestProcessor(t *testing.T, dir string, fields ...string) *Processor {
+	t.Helper()
+	opts := DefaultOptions()
+	opts.Directory = dir
+	opts.Fields = fields
+	p, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// writeFiles writes files, keyed by slash-separated paths, under root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"current.py":   "# codemap: v=1;path=current.py;lang=Python\n",
+		"missing.py":   "#!/usr/bin/env python3\nx = 1\n",
+		"stale.py":     "# codemap: v=1;path=other.py;lang=Go\n",
+		"malformed.py": "# codemap: v=1;lang\n",
+		"template.php": "<html></html>\n",
+	})
+
+	findings, err := newTestProcessor(t, dir, "path", "lang").Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		kind    FindingKind
+		line    int
+		message string
+	}
+	wants := map[string]want{
+		"missing.py":   {FindingMissing, 2, "file has no codemap annotation"},
+		"stale.py":     {FindingStale, 1, `annotation is stale: path is "other.py", want "stale.py"; lang is "Go", want "Python"`},
+		"malformed.py": {FindingMalformed, 1, "malformed codemap annotation: field \"lang\" has no value"},
+	}
+	if len(findings) != len(wants) {
+		t.Errorf("got %d findings, want %d: %+v", len(findings), len(wants), findings)
+	}
+	for _, f := range findings {
+		name := filepath.Base(f.Path)
+		w, ok := wants[name]
+		if !ok {
+			t.Errorf("unexpected finding for %s: %+v", name, f)
+			continue
+		}
+		if f.Kind != w.kind || f.Line != w.line || f.Message != w.message {
+			t.Errorf("%s: got %s on line %d %q, want %s on line %d %q", name, f.Kind, f.Line, f.Message, w.kind, w.line, w.message)
+		}
+		if strings.HasPrefix(f.Message, string(f.Kind)+":") {
+			t.Errorf("%s: message %q repeats the kind", name, f.Message)
+		}
+	}
+}
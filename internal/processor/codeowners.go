@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// codeOwnersFiles are the places GitHub and GitLab look for CODEOWNERS,
+// relative to the repository root, in order of precedence
+var codeOwnersFiles = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// codeOwnersRule is one pattern line of a CODEOWNERS file
+type codeOwnersRule struct {
+	patterns []glob.Glob
+	owners   []string
+}
+
+// matches reports whether any of the rule's globs matches relPath
+func (r codeOwnersRule) matches(relPath string) bool {
+	for _, g := range r.patterns {
+		if g.Match(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// codeOwners matches repository-relative paths against CODEOWNERS rules
+type codeOwners struct {
+	rules []codeOwnersRule
+}
+
+// loadCodeOwners reads the first CODEOWNERS file found under root. A missing
+// file yields an empty rule set.
+func loadCodeOwners(root string) *codeOwners {
+	co := &codeOwners{}
+	for _, name := range codeOwnersFiles {
+		lines, err := readLines(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			continue
+		}
+
+		for _, text := range lines {
+			line := strings.TrimSpace(text)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+				continue
+			}
+			fields := strings.Fields(line)
+			rule := codeOwnersRule{owners: fields[1:]}
+			for _, pattern := range codeOwnersGlobs(fields[0]) {
+				if g, err := glob.Compile(pattern, '/'); err == nil {
+					rule.patterns = append(rule.patterns, g)
+				}
+			}
+			if len(rule.patterns) > 0 {
+				co.rules = append(co.rules, rule)
+			}
+		}
+		break
+	}
+	return co
+}
+
+// codeOwnersGlobs converts a gitignore-style CODEOWNERS pattern into globs
+// matched against file paths relative to the repository root. A directory
+// pattern owns everything below it, and so does a pattern naming a file or
+// directory without wildcards, but a wildcard matches one level only: docs/*
+// owns the files in docs and not those in its subdirectories.
+// The globs are kept apart rather than joined in braces, which gobwas/glob
+// does not match reliably when one alternative starts with "**/".
+func codeOwnersGlobs(pattern string) []string {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	alternatives := []string{pattern}
+	if strings.HasSuffix(pattern, "/") {
+		alternatives = []string{pattern + "**"}
+	} else if !strings.ContainsAny(path.Base(pattern), "*?[") {
+		alternatives = append(alternatives, pattern+"/**")
+	}
+	if !anchored {
+		for _, alt := range alternatives {
+			alternatives = append(alternatives, "**/"+alt)
+		}
+	}
+	return alternatives
+}
+
+// owners returns the owners of the last rule matching relPath, as CODEOWNERS
+// gives the last match precedence
+func (co *codeOwners) owners(relPath string) []string {
+	relPath = filepath.ToSlash(relPath)
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].matches(relPath) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	root := t.TempDir()
+	rules := `# Comment
+* @everyone
+*.go @gophers
+docs/* @docs
+apps/ @apps
+/build/logs/ @logs
+scripts @scripts
+/src/**/*.ts @ts
+README.md @readme
+`
+	if err := os.WriteFile(filepath.Join(root, "CODEOWNERS"), []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	co := loadCodeOwners(root)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"main.go", []string{"@gophers"}},
+		{"cmd/tool/main.go", []string{"@gophers"}},
+		{"docs/index.md", []string{"@docs"}},
+		{"docs/build-app/troubleshooting.md", []string{"@everyone"}},
+		{"apps/web/index.html", []string{"@apps"}},
+		{"services/apps/web/index.html", []string{"@apps"}},
+		{"build/logs/today.log", []string{"@logs"}},
+		{"x/build/logs/today.log", []string{"@everyone"}},
+		{"scripts", []string{"@scripts"}},
+		{"tools/scripts/run.sh", []string{"@scripts"}},
+		{"src/a/b/c.ts", []string{"@ts"}},
+		{"lib/src/c.ts", []string{"@everyone"}},
+		{"pkg/README.md", []string{"@readme"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := co.owners(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("owners(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeOwnersMissing(t *testing.T) {
+	if got := loadCodeOwners(t.TempDir()).owners("a.go"); got != nil {
+		t.Errorf("owners() = %v, want none", got)
+	}
+}
@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"os"
+	"strings"
+)
+
+// readConfigSections calls fn for each key of an INI-style file such as
+// setup.cfg, or the simple tables of a TOML file such as pyproject.toml.
+// With indented set, indented lines continue the previous value as in
+// setup.cfg. Otherwise # comments are dropped as in TOML. A TOML array that
+// spans lines is joined into one value.
+func readConfigSections(file string, indented bool, fn func(section, key, value string)) {
+	lines, err := readLines(file)
+	if err != nil {
+		return
+	}
+
+	var section, key, value string
+	flush := func() {
+		if key != "" {
+			fn(section, key, strings.TrimSpace(value))
+		}
+		key, value = "", ""
+	}
+
+	for _, line := range lines {
+		if !indented {
+			line = stripYAMLComment(line) // TOML comments work the same way
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			continue
+		case key != "" && ((indented && (line[0] == ' ' || line[0] == '\t')) || strings.Count(value, "[") > strings.Count(value, "]")):
+			value += "\n" + trimmed
+		case strings.HasPrefix(trimmed, "["):
+			flush()
+			section = strings.TrimSpace(strings.Trim(trimmed, "[]"))
+		default:
+			flush()
+			k, v, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				k, v, ok = strings.Cut(trimmed, ":")
+			}
+			if ok {
+				key, value = strings.TrimSpace(k), v
+			}
+		}
+	}
+	flush()
+}
+
+// unquoteTOML returns the content of a TOML basic or literal string
+func unquoteTOML(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') {
+		if end := strings.IndexByte(s[1:], s[0]); end >= 0 {
+			return s[1 : end+1]
+		}
+	}
+	return s
+}
+
+// tomlStrings returns the strings of a TOML array such as ["src", "lib"]
+func tomlStrings(s string) []string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") {
+		return []string{unquoteTOML(s)}
+	}
+	var values []string
+	for _, item := range strings.Split(strings.Trim(s, "[]"), ",") {
+		if item = unquoteTOML(strings.TrimSpace(item)); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// readLines returns the lines of file without their terminators. Unlike a
+// bufio.Scanner it has no limit on line length, so minified or generated
+// files cannot cut a read short.
+func readLines(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return nil, nil
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, nil
+}
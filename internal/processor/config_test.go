@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	long := strings.Repeat("x", 256*1024)
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"empty", "", nil},
+		{"terminated", "a\nb\n", []string{"a", "b"}},
+		{"unterminated", "a\nb", []string{"a", "b"}},
+		{"crlf", "a\r\nb\r\n", []string{"a", "b"}},
+		{"blank lines", "a\n\nb\n", []string{"a", "", "b"}},
+		{"long line", long + "\nb\n", []string{long, "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "f")
+			if err := os.WriteFile(file, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readLines(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readLines() = %d lines, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+
+	if _, err := readLines(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("readLines() of a missing file succeeded")
+	}
+}
+
+func TestReadConfigSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		indented bool
+		want     map[string]string
+	}{
+		{
+			name:    "toml",
+			content: "[project]\nname = \"acme\" # the name\n\n[tool.setuptools]\npackages = [\n  \"a\", # first\n  \"b\",\n]\n",
+			want: map[string]string{
+				"project.name":             `"acme"`,
+				"tool.setuptools.packages": "[\n\"a\",\n\"b\",\n]",
+			},
+		},
+		{
+			name:     "setup.cfg",
+			content:  "[metadata]\nname = acme\n\n[options]\npackages =\n    a\n    b\n",
+			indented: true,
+			want: map[string]string{
+				"metadata.name":    "acme",
+				"options.packages": "a\nb",
+			},
+		},
+		{
+			name:    "after a long line",
+			content: "[project]\ndescription = \"" + strings.Repeat("x", 100*1024) + "\"\nname = \"acme\"\n",
+			want: map[string]string{
+				"project.description": `"` + strings.Repeat("x", 100*1024) + `"`,
+				"project.name":        `"acme"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(file, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			readConfigSections(file, tt.indented, func(section, key, value string) {
+				got[section+"."+key] = value
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readConfigSections() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+var (
+	// csharpNamespace matches a file-scoped or block namespace declaration
+	csharpNamespace = regexp.MustCompile(`(?m)^\s*namespace\s+([\w.]+)`)
+	// csprojAssemblyName matches the AssemblyName property of a project file
+	csprojAssemblyName = regexp.MustCompile(`<AssemblyName>\s*([^<]+?)\s*</AssemblyName>`)
+)
+
+// resolveCSharp takes the package from the first namespace declaration and
+// the module from the nearest .csproj
+func resolveCSharp(p *Processor, f *sourceFile) packageInfo {
+	info := packageInfo{Fields: map[string]string{}}
+	if data, err := os.ReadFile(f.path); err == nil {
+		if m := csharpNamespace.FindSubmatch(cStyleComment.ReplaceAll(data, nil)); m != nil {
+			info.Package = string(m[1])
+		}
+	}
+	if project := p.manifests.nearest(filepath.Dir(f.abs), "*.csproj"); project != "" {
+		info.Fields[annotator.KeyModule] = csprojName(project)
+	}
+	return info
+}
+
+// csprojName returns the assembly name of a project, which defaults to the
+// project file name
+func csprojName(file string) string {
+	if data, err := os.ReadFile(file); err == nil {
+		if m := csprojAssemblyName.FindSubmatch(data); m != nil && !strings.Contains(string(m[1]), "$(") {
+			return string(m[1])
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+func TestResolveCSharp(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"src/Billing/Billing.csproj": "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <PropertyGroup>\n    <AssemblyName> Acme.Billing </AssemblyName>\n  </PropertyGroup>\n</Project>\n",
+		"src/Billing/Invoice.cs":     "using System;\n\nnamespace Acme.Billing;\n\npublic class Invoice {}\n",
+		"src/Billing/Models/Line.cs": "// namespace Wrong;\n/* namespace AlsoWrong { } */\nnamespace Acme.Billing.Models\n{\n    namespace Inner { }\n}\n",
+		"src/Tools/Tools.csproj":     "<Project><PropertyGroup><AssemblyName>$(MSBuildProjectName).Cli</AssemblyName></PropertyGroup></Project>\n",
+		"src/Tools/Program.cs":       "Console.WriteLine(\"hi\");\n",
+		"scripts/Loose.cs":           "namespace Loose;\n",
+	})
+
+	tests := []struct {
+		path, pkg, module string
+	}{
+		{"src/Billing/Invoice.cs", "Acme.Billing", "Acme.Billing"},
+		{"src/Billing/Models/Line.cs", "Acme.Billing.Models", "Acme.Billing"},
+		// An AssemblyName built from properties falls back to the file name
+		{"src/Tools/Program.cs", "", "Tools"},
+		{"scripts/Loose.cs", "Loose", ""},
+	}
+
+	p := newTestProcessor(t, root, "path")
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info := resolveCSharp(p, newSourceFile(filepath.Join(root, filepath.FromSlash(tt.path))))
+			if info.Package != tt.pkg || info.Fields[annotator.KeyModule] != tt.module {
+				t.Errorf("got package %q module %q, want %q %q", info.Package, info.Fields[annotator.KeyModule], tt.pkg, tt.module)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/krzko/codemap/internal/languages"
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// DefaultFields lists the annotation fields written when Options.Fields is empty
+var DefaultFields = []string{
+	annotator.KeyPath,
+	annotator.KeyPackage,
+	annotator.KeyPackagePath,
+	annotator.KeyModule,
+	annotator.KeyLanguage,
+	annotator.KeyImport,
+	annotator.KeyBuild,
+	annotator.KeyTest,
+	annotator.KeyMigration,
+	annotator.KeyGoPackage,
+	annotator.KeyJavaPackage,
+}
+
+// fieldProvider computes the value of one annotation field. Returning false
+// leaves the field out of the annotation for that file.
+type fieldProvider func(p *Processor, f *sourceFile) (string, bool)
+
+// fieldProviders maps field names accepted by --fields to their providers
+var fieldProviders = map[string]fieldProvider{
+	annotator.KeyPath:        pathField,
+	annotator.KeyPackage:     packageField,
+	annotator.KeyPackagePath: resolvedField(annotator.KeyPackagePath),
+	annotator.KeyModule:      resolvedField(annotator.KeyModule),
+	annotator.KeyLanguage:    languageField,
+	annotator.KeyImport:      importField,
+	annotator.KeyBuild:       resolvedField(annotator.KeyBuild),
+	annotator.KeyTest:        resolvedField(annotator.KeyTest),
+	annotator.KeyMigration:   resolvedField(annotator.KeyMigration),
+	annotator.KeyGoPackage:   resolvedField(annotator.KeyGoPackage),
+	annotator.KeyJavaPackage: resolvedField(annotator.KeyJavaPackage),
+	"loc":                    locField,
+	"hash":                   hashField,
+	"owner":                  ownerField,
+	"role":                   roleField,
+}
+
+// FieldNames returns the names of all available fields, sorted
+func FieldNames() []string {
+	names := make([]string, 0, len(fieldProviders))
+	for name := range fieldProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateFields checks that every name in fields has a provider and that the
+// path field, which identifies an annotation, is included
+func validateFields(fields []string) error {
+	seen := make(map[string]bool, len(fields))
+	for _, name := range fields {
+		if _, ok := fieldProviders[name]; !ok {
+			return fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(FieldNames(), ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("field %q is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	if !seen[annotator.KeyPath] {
+		return fmt.Errorf("the %s field is required", annotator.KeyPath)
+	}
+	return nil
+}
+
+// sourceFile caches what providers learn about a file, so fields that share a
+// source, such as the package resolver or the file content, read it once
+type sourceFile struct {
+	path string
+	abs  string
+	lang languages.Language // nil for files no language handles
+
+	pkgOnce sync.Once
+	pkgInfo packageInfo
+
+	contentOnce sync.Once
+	content     string
+	contentErr  error
+}
+
+func newSourceFile(path string) *sourceFile {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	lang, _ := languages.ForPath(path)
+	return &sourceFile{path: path, abs: abs, lang: lang}
+}
+
+// langName returns the name of the file's language, or "Unknown"
+func (f *sourceFile) langName() string {
+	if f.lang == nil {
+		return "Unknown"
+	}
+	return f.lang.Name()
+}
+
+// pkg runs the language's resolver on first use
+func (f *sourceFile) pkg(p *Processor) packageInfo {
+	f.pkgOnce.Do(func() {
+		if resolve, ok := resolvers[f.langName()]; ok {
+			f.pkgInfo = resolve(p, f)
+		}
+		if f.pkgInfo.Package == "" {
+			f.pkgInfo.Package = dirPackage(f.path)
+		}
+	})
+	return f.pkgInfo
+}
+
+// body returns the file content without its codemap annotation
+func (f *sourceFile) body(p *Processor) (string, error) {
+	f.contentOnce.Do(func() {
+		content, err := os.ReadFile(f.path)
+		if err != nil {
+			f.contentErr = err
+			return
+		}
+		f.content = p.annotator.StripAnnotation(f.path, string(content))
+	})
+	return f.content, f.contentErr
+}
+
+func pathField(p *Processor, f *sourceFile) (string, bool) {
+	return p.paths.format(p.opts.PathMode, f.abs, f.pkg(p).ImportDir), true
+}
+
+func packageField(p *Processor, f *sourceFile) (string, bool) {
+	return f.pkg(p).Package, true
+}
+
+func languageField(p *Processor, f *sourceFile) (string, bool) {
+	return f.langName(), true
+}
+
+func importField(p *Processor, f *sourceFile) (string, bool) {
+	importPath := f.pkg(p).Import
+	return importPath, importPath != ""
+}
+
+// resolvedField returns a provider for a language-specific field set by the
+// file's resolver
+func resolvedField(key string) fieldProvider {
+	return func(p *Processor, f *sourceFile) (string, bool) {
+		value, ok := f.pkg(p).Fields[key]
+		return value, ok && value != ""
+	}
+}
+
+// locField counts the lines of the file, not including the annotation
+func locField(p *Processor, f *sourceFile) (string, bool) {
+	body, err := f.body(p)
+	if err != nil {
+		return "", false
+	}
+	lines := strings.Count(body, "\n")
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		lines++
+	}
+	return strconv.Itoa(lines), true
+}
+
+// hashField hashes the file content, not including the annotation, so the
+// value only changes when the code does
+func hashField(p *Processor, f *sourceFile) (string, bool) {
+	body, err := f.body(p)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(body))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12], true
+}
+
+// ownerField lists the owners of the file from the repository's CODEOWNERS
+func ownerField(p *Processor, f *sourceFile) (string, bool) {
+	owners := p.codeOwners().owners(relSlash(p.paths.gitRoot, f.abs))
+	return strings.Join(owners, " "), len(owners) > 0
+}
+
+// generatedMarker matches the standard "Code generated ... DO NOT EDIT." line
+var generatedMarker = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// roleField classifies the file as generated, test, main or source
+func roleField(p *Processor, f *sourceFile) (string, bool) {
+	if body, err := f.body(p); err == nil && generatedMarker.MatchString(body) {
+		return "generated", true
+	}
+
+	base := filepath.Base(f.path)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py"),
+		strings.HasSuffix(base, "_test.py"),
+		strings.HasSuffix(stem, ".test"),
+		strings.HasSuffix(stem, ".spec"):
+		return "test", true
+	case f.langName() == "Go" && f.pkg(p).Package == "main":
+		return "main", true
+	}
+	return "source", true
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// goFile holds what codemap reads from the header of a Go source file
+type goFile struct {
+	// Package is the name from the package clause
+	Package string
+	// BuildConstraint is the file's build constraint expression, if any
+	BuildConstraint string
+	// ExternalTest marks a _test.go file in a separate foo_test package
+	ExternalTest bool
+}
+
+// readGoFile parses the package clause and the comments above it. Package is
+// "unknown" if the file cannot be parsed.
+func readGoFile(path string) goFile {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil || f.Name == nil {
+		return goFile{Package: "unknown"}
+	}
+
+	gf := goFile{
+		Package: f.Name.Name,
+		ExternalTest: strings.HasSuffix(filepath.Base(path), "_test.go") &&
+			strings.HasSuffix(f.Name.Name, "_test"),
+	}
+
+	// Build constraints are only honoured above the package clause. A
+	// //go:build line supersedes any legacy // +build lines, which are ANDed.
+	var goBuild constraint.Expr
+	var plusBuild []constraint.Expr
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, c := range group.List {
+			switch {
+			case constraint.IsGoBuild(c.Text):
+				if expr, err := constraint.Parse(c.Text); err == nil && goBuild == nil {
+					goBuild = expr
+				}
+			case constraint.IsPlusBuild(c.Text):
+				if expr, err := constraint.Parse(c.Text); err == nil {
+					plusBuild = append(plusBuild, expr)
+				}
+			}
+		}
+	}
+
+	switch {
+	case goBuild != nil:
+		gf.BuildConstraint = goBuild.String()
+	case len(plusBuild) > 0:
+		expr := plusBuild[0]
+		for _, next := range plusBuild[1:] {
+			expr = &constraint.AndExpr{X: expr, Y: next}
+		}
+		gf.BuildConstraint = expr.String()
+	}
+
+	return gf
+}
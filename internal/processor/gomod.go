@@ -0,0 +1,205 @@
+package processor
+
+import (
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// goModules resolves Go import paths from go.mod and go.work files. Lookups are
+// cached per directory since every file in a package asks the same question.
+type goModules struct {
+	mu         sync.Mutex
+	roots      map[string]string   // directory -> enclosing module root ("" if none)
+	paths      map[string]string   // module root -> module path
+	workspaces map[string]*goWork  // directory -> enclosing go.work (nil if none)
+	warned     map[string]struct{} // module roots already reported as outside their workspace
+}
+
+// goWork is a parsed go.work file
+type goWork struct {
+	file string
+	uses map[string]struct{} // absolute module directories from use directives
+}
+
+func newGoModules() *goModules {
+	return &goModules{
+		roots:      make(map[string]string),
+		paths:      make(map[string]string),
+		workspaces: make(map[string]*goWork),
+		warned:     make(map[string]struct{}),
+	}
+}
+
+// importPath returns the import path of the package containing the Go file at
+// absPath, or "" when the file is not inside a module
+func (m *goModules) importPath(absPath string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dir := filepath.Dir(absPath)
+	root := m.moduleRoot(dir)
+	if root == "" {
+		return ""
+	}
+
+	// In workspace mode the go command only builds modules listed in go.work
+	if work := m.workspace(dir); work != nil {
+		if _, ok := work.uses[root]; !ok {
+			if _, seen := m.warned[root]; !seen {
+				m.warned[root] = struct{}{}
+				log.Printf("Module %s is not listed in %s", root, work.file)
+			}
+		}
+	}
+
+	modPath, ok := m.paths[root]
+	if !ok {
+		modPath = readModulePath(filepath.Join(root, "go.mod"))
+		m.paths[root] = modPath
+	}
+	if modPath == "" {
+		return ""
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return modPath
+	}
+	return path.Join(modPath, filepath.ToSlash(rel))
+}
+
+// moduleRoot returns the nearest directory at or above dir containing a go.mod
+func (m *goModules) moduleRoot(dir string) string {
+	if root, ok := m.roots[dir]; ok {
+		return root
+	}
+
+	var root string
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		root = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		root = m.moduleRoot(parent)
+	}
+
+	m.roots[dir] = root
+	return root
+}
+
+// workspace returns the go.work file that applies to dir, honouring GOWORK
+func (m *goModules) workspace(dir string) *goWork {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return nil
+	case "":
+	default:
+		return m.loadWorkspace("GOWORK", gowork)
+	}
+
+	if work, ok := m.workspaces[dir]; ok {
+		return work
+	}
+
+	var work *goWork
+	if file := filepath.Join(dir, "go.work"); fileExists(file) {
+		work = readGoWork(file)
+	} else if parent := filepath.Dir(dir); parent != dir {
+		work = m.workspace(parent)
+	}
+
+	m.workspaces[dir] = work
+	return work
+}
+
+// loadWorkspace reads file once and caches it under key
+func (m *goModules) loadWorkspace(key, file string) *goWork {
+	if work, ok := m.workspaces[key]; ok {
+		return work
+	}
+	work := readGoWork(file)
+	m.workspaces[key] = work
+	return work
+}
+
+// readModulePath returns the path from the module directive of a go.mod file
+func readModulePath(file string) string {
+	lines, err := readLines(file)
+	if err != nil {
+		return ""
+	}
+
+	for _, text := range lines {
+		fields := strings.Fields(stripModComment(text))
+		if len(fields) >= 2 && fields[0] == "module" {
+			return unquoteModPath(fields[1])
+		}
+	}
+	return ""
+}
+
+// readGoWork parses the use directives of a go.work file, in both the single
+// line and the block form. It returns nil if the file cannot be read.
+func readGoWork(file string) *goWork {
+	lines, err := readLines(file)
+	if err != nil {
+		return nil
+	}
+
+	work := &goWork{file: file, uses: make(map[string]struct{})}
+	base := filepath.Dir(file)
+	inBlock := false
+
+	for _, text := range lines {
+		fields := strings.Fields(stripModComment(text))
+		if len(fields) == 0 {
+			continue
+		}
+
+		var dir string
+		switch {
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			dir = fields[0]
+		case fields[0] == "use" && len(fields) >= 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "use" && len(fields) >= 2:
+			dir = fields[1]
+		}
+
+		if dir != "" {
+			dir = unquoteModPath(dir)
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(base, dir)
+			}
+			work.uses[filepath.Clean(dir)] = struct{}{}
+		}
+	}
+
+	return work
+}
+
+// stripModComment removes a trailing // comment from a go.mod or go.work line
+func stripModComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// unquoteModPath removes the quotes allowed around paths in go.mod and go.work
+func unquoteModPath(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// kustomizationFiles are the names kustomize reads a kustomization from
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// resolveTerraform names the package after the module, which in Terraform is
+// the directory, written relative to the repository root
+func resolveTerraform(p *Processor, f *sourceFile) packageInfo {
+	return packageInfo{Package: repoDir(p, filepath.Dir(f.abs))}
+}
+
+// resolveHelm names the package after the chart the template belongs to
+func resolveHelm(p *Processor, f *sourceFile) packageInfo {
+	return packageInfo{Package: chartName(p, filepath.Dir(f.abs))}
+}
+
+// resolveYAML names the package after the Helm chart, GitHub Actions workflow
+// or action, or Kustomize base or overlay the file belongs to
+func resolveYAML(p *Processor, f *sourceFile) packageInfo {
+	dir := filepath.Dir(f.abs)
+	base := filepath.Base(f.abs)
+
+	if name := chartName(p, dir); name != "" {
+		return packageInfo{Package: name}
+	}
+	if base == "action.yml" || base == "action.yaml" || relSlash(p.paths.gitRoot, dir) == ".github/workflows" {
+		name := readYAMLKey(f.abs, "name")
+		if name == "" {
+			name = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		return packageInfo{Package: name}
+	}
+	for d := dir; isWithin(p.paths.gitRoot, d); d = filepath.Dir(d) {
+		for _, name := range kustomizationFiles {
+			if fileExists(filepath.Join(d, name)) {
+				return packageInfo{Package: repoDir(p, d)}
+			}
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return packageInfo{}
+}
+
+// chartName returns the name in the nearest Chart.yaml at or above dir, within
+// the repository
+func chartName(p *Processor, dir string) string {
+	for d := dir; isWithin(p.paths.gitRoot, d); d = filepath.Dir(d) {
+		if file := filepath.Join(d, "Chart.yaml"); fileExists(file) {
+			if name := readYAMLKey(file, "name"); name != "" {
+				return name
+			}
+			return filepath.Base(d)
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return ""
+}
+
+// repoDir returns dir relative to the repository root, or its name for the
+// root itself
+func repoDir(p *Processor, dir string) string {
+	if rel := relSlash(p.paths.gitRoot, dir); rel != "." && !strings.HasPrefix(rel, "../") {
+		return rel
+	}
+	return filepath.Base(dir)
+}
+
+// readYAMLKey returns the plain or quoted scalar value of a top-level key in
+// the first document of a YAML file
+func readYAMLKey(file, key string) string {
+	lines, err := readLines(file)
+	if err != nil {
+		return ""
+	}
+
+	started := false
+	for _, text := range lines {
+		line := stripYAMLComment(text)
+		if strings.TrimSpace(line) == "---" {
+			if started {
+				break
+			}
+			started = true
+			continue
+		}
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		started = true
+		if k, v, ok := strings.Cut(line, ":"); ok && unquoteYAML(k) == key {
+			return unquoteYAML(v)
+		}
+	}
+	return ""
+}
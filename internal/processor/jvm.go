@@ -0,0 +1,172 @@
+package processor
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// jvmPackageClause matches the package declaration of a Java or Kotlin file,
+// once comments are removed
+var jvmPackageClause = regexp.MustCompile(`(?m)^\s*package\s+([\p{L}_$][\p{L}\p{N}_$]*(?:\s*\.\s*[\p{L}_$][\p{L}\p{N}_$]*)*)`)
+
+// cStyleComment matches // and /* */ comments
+var cStyleComment = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+
+// jvmModules finds the Maven or Gradle modules JVM sources belong to
+type jvmModules struct {
+	mu       sync.Mutex
+	modules  map[string]string          // directory -> enclosing module name ("" if none)
+	settings map[string]*gradleSettings // settings file -> parsed settings
+}
+
+// gradleSettings is what codemap reads from settings.gradle(.kts)
+type gradleSettings struct {
+	rootName string
+	projects map[string]string // project directory -> project path such as ":lib:core"
+}
+
+func newJVMModules() *jvmModules {
+	return &jvmModules{
+		modules:  make(map[string]string),
+		settings: make(map[string]*gradleSettings),
+	}
+}
+
+// resolveJava takes the package from the package declaration and the module
+// from the build. The import is the class named after the file.
+func resolveJava(p *Processor, f *sourceFile) packageInfo {
+	info := resolveKotlin(p, f)
+	stem := strings.TrimSuffix(filepath.Base(f.path), filepath.Ext(f.path))
+	if info.Package != "" && stem != "package-info" && stem != "module-info" {
+		info.Import = info.Package + "." + stem
+	}
+	return info
+}
+
+// resolveKotlin takes the package from the package declaration and the module
+// from the build
+func resolveKotlin(p *Processor, f *sourceFile) packageInfo {
+	info := packageInfo{Fields: map[string]string{
+		annotator.KeyModule: p.jvmModules.find(filepath.Dir(f.abs)),
+	}}
+	if data, err := os.ReadFile(f.path); err == nil {
+		if m := jvmPackageClause.FindSubmatch(cStyleComment.ReplaceAll(data, nil)); m != nil {
+			info.Package = strings.Join(strings.Fields(string(m[1])), "")
+		}
+	}
+	return info
+}
+
+// find returns the module owning dir: the artifactId of the nearest pom.xml,
+// or the Gradle project path of the nearest build.gradle(.kts)
+func (j *jvmModules) find(dir string) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.findLocked(dir)
+}
+
+func (j *jvmModules) findLocked(dir string) string {
+	if module, ok := j.modules[dir]; ok {
+		return module
+	}
+
+	var module string
+	switch {
+	case fileExists(filepath.Join(dir, "pom.xml")):
+		module = readArtifactID(filepath.Join(dir, "pom.xml"))
+	case fileExists(filepath.Join(dir, "build.gradle")), fileExists(filepath.Join(dir, "build.gradle.kts")):
+		module = j.gradleProject(dir)
+	default:
+		if parent := filepath.Dir(dir); parent != dir {
+			module = j.findLocked(parent)
+		}
+	}
+	j.modules[dir] = module
+	return module
+}
+
+// readArtifactID returns the artifactId of the project in a pom.xml, not that
+// of its parent
+func readArtifactID(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	var pom struct {
+		ArtifactID string `xml:"artifactId"`
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(pom.ArtifactID)
+}
+
+// gradleProject returns the project path of the Gradle project in dir, as
+// included by the nearest settings file, or the root project name. Without a
+// settings file the directory name is the project name, as in Gradle.
+func (j *jvmModules) gradleProject(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+			file := filepath.Join(d, name)
+			if !fileExists(file) {
+				continue
+			}
+			settings, ok := j.settings[file]
+			if !ok {
+				settings = readGradleSettings(file)
+				j.settings[file] = settings
+			}
+			if d == dir {
+				return settings.rootName
+			}
+			return settings.projects[dir]
+		}
+		if filepath.Dir(d) == d {
+			return filepath.Base(dir)
+		}
+	}
+}
+
+var (
+	// gradleInclude matches an include statement in Groovy or Kotlin DSL
+	gradleInclude = regexp.MustCompile(`(?m)^\s*include\s*\(?([^)\n]*)`)
+	// gradleRootName matches rootProject.name = "..."
+	gradleRootName = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+	// gradleString matches a quoted string
+	gradleString = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// readGradleSettings reads the root project name and included projects from a
+// settings file. Included projects live in the directory their path names,
+// so ":lib:core" is lib/core.
+func readGradleSettings(file string) *gradleSettings {
+	dir := filepath.Dir(file)
+	settings := &gradleSettings{rootName: filepath.Base(dir), projects: make(map[string]string)}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return settings
+	}
+	data = cStyleComment.ReplaceAll(data, nil)
+
+	if m := gradleRootName.FindSubmatch(data); m != nil {
+		settings.rootName = string(m[1])
+	}
+	for _, include := range gradleInclude.FindAllSubmatch(data, -1) {
+		for _, m := range gradleString.FindAllSubmatch(include[1], -1) {
+			projectPath := string(m[1])
+			if !strings.HasPrefix(projectPath, ":") {
+				projectPath = ":" + projectPath
+			}
+			rel := strings.ReplaceAll(strings.TrimPrefix(projectPath, ":"), ":", string(filepath.Separator))
+			settings.projects[filepath.Join(dir, rel)] = projectPath
+		}
+	}
+	return settings
+}
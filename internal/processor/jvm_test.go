@@ -0,0 +1,158 @@
+package processor
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadArtifactID(t *testing.T) {
+	tests := []struct {
+		name string
+		pom  string
+		want string
+	}{
+		{
+			name: "project",
+			pom:  `<project><modelVersion>4.0.0</modelVersion><groupId>com.acme</groupId><artifactId>billing</artifactId></project>`,
+			want: "billing",
+		},
+		{
+			name: "parent first",
+			pom: `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <groupId>com.acme</groupId>
+    <artifactId>acme-parent</artifactId>
+  </parent>
+  <!-- <artifactId>commented</artifactId> -->
+  <artifactId>
+    billing-api
+  </artifactId>
+  <dependencies>
+    <dependency><artifactId>junit</artifactId></dependency>
+  </dependencies>
+</project>`,
+			want: "billing-api",
+		},
+		{name: "no artifactId", pom: `<project><groupId>com.acme</groupId></project>`, want: ""},
+		{name: "invalid", pom: `<project><artifactId>x`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"pom.xml": tt.pom})
+			if got := readArtifactID(filepath.Join(dir, "pom.xml")); got != tt.want {
+				t.Errorf("readArtifactID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := readArtifactID(filepath.Join(t.TempDir(), "pom.xml")); got != "" {
+		t.Errorf("readArtifactID() of a missing file = %q", got)
+	}
+}
+
+func TestReadGradleSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		settings string
+		rootName string
+		projects map[string]string // relative directory -> project path
+	}{
+		{
+			name:     "groovy",
+			file:     "settings.gradle",
+			settings: "rootProject.name = 'acme'\ninclude 'app', ':lib:core'\ninclude \":lib:util\"\n",
+			rootName: "acme",
+			projects: map[string]string{"app": ":app", "lib/core": ":lib:core", "lib/util": ":lib:util"},
+		},
+		{
+			name:     "kotlin",
+			file:     "settings.gradle.kts",
+			settings: "rootProject.name = \"acme\"\n\ninclude(\":app\", \":server\")\n",
+			rootName: "acme",
+			projects: map[string]string{"app": ":app", "server": ":server"},
+		},
+		{
+			name:     "comments",
+			file:     "settings.gradle.kts",
+			settings: "// include(\":old\")\n/*\ninclude(\":older\")\n*/\ninclude(\":app\") // the app\n",
+			rootName: "",
+			projects: map[string]string{"app": ":app"},
+		},
+		{
+			name:     "no root name",
+			file:     "settings.gradle",
+			settings: "include 'app'\n",
+			rootName: "",
+			projects: map[string]string{"app": ":app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{tt.file: tt.settings})
+
+			settings := readGradleSettings(filepath.Join(dir, tt.file))
+			wantRoot := tt.rootName
+			if wantRoot == "" {
+				wantRoot = filepath.Base(dir)
+			}
+			if settings.rootName != wantRoot {
+				t.Errorf("rootName = %q, want %q", settings.rootName, wantRoot)
+			}
+			projects := map[string]string{}
+			for d, path := range settings.projects {
+				projects[relSlash(dir, d)] = path
+			}
+			if !reflect.DeepEqual(projects, tt.projects) {
+				t.Errorf("projects = %v, want %v", projects, tt.projects)
+			}
+		})
+	}
+}
+
+func TestResolveJVM(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"maven/pom.xml":                                  "<project><artifactId>maven-app</artifactId></project>",
+		"maven/src/main/java/com/acme/App.java":          "/* package com.wrong; */\npackage com.acme;\n\npublic class App {}\n",
+		"maven/src/main/java/com/acme/package-info.java": "// docs\npackage com.acme;\n",
+		"gradle/settings.gradle.kts":                     "rootProject.name = \"shop\"\ninclude(\":lib:core\")\n",
+		"gradle/build.gradle.kts":                        "",
+		"gradle/lib/core/build.gradle.kts":               "",
+		"gradle/lib/core/src/main/kotlin/Core.kt":        "package com.acme.core\n\nclass Core\n",
+		"gradle/src/main/kotlin/Main.kt":                 "package com.acme\n",
+		"loose/Tool.java":                                "public class Tool {}\n",
+	})
+
+	tests := []struct {
+		path, pkg, imp, module string
+	}{
+		{"maven/src/main/java/com/acme/App.java", "com.acme", "com.acme.App", "maven-app"},
+		{"maven/src/main/java/com/acme/package-info.java", "com.acme", "", "maven-app"},
+		{"gradle/lib/core/src/main/kotlin/Core.kt", "com.acme.core", "", ":lib:core"},
+		{"gradle/src/main/kotlin/Main.kt", "com.acme", "", "shop"},
+		{"loose/Tool.java", "", "", ""},
+	}
+
+	p := newTestProcessor(t, root, "path")
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			f := newSourceFile(filepath.Join(root, filepath.FromSlash(tt.path)))
+			resolve := resolveKotlin
+			if filepath.Ext(tt.path) == ".java" {
+				resolve = resolveJava
+			}
+			info := resolve(p, f)
+			if info.Package != tt.pkg || info.Import != tt.imp || info.Fields["module"] != tt.module {
+				t.Errorf("got pkg %q import %q module %q, want %q %q %q",
+					info.Package, info.Import, info.Fields["module"], tt.pkg, tt.imp, tt.module)
+			}
+		})
+	}
+}
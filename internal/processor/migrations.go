@@ -0,0 +1,207 @@
+package processor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// migration describes a file that is part of a schema migration sequence
+type migration struct {
+	// Ordinal is the position of the migration in its sequence, as its tool
+	// orders them. Empty for Flyway repeatable migrations, which have none.
+	Ordinal string
+	// Flyway is set for Flyway migrations, whose checksums Flyway validates
+	Flyway bool
+}
+
+var (
+	// flywayMigration matches V1__name.sql, U1.2__name.sql and R__name.sql
+	flywayMigration = regexp.MustCompile(`^(?:([VU])(\d+(?:[._]\d+)*)|R)__.+\.sql$`)
+	// versionedMigration matches golang-migrate (1_name.up.sql), goose and
+	// dbmate (20240101120000_name.sql) file names
+	versionedMigration = regexp.MustCompile(`^(\d+)_[^.]+(?:\.(?:up|down))?\.sql$`)
+	// djangoMigration matches 0001_initial.py
+	djangoMigration = regexp.MustCompile(`^(\d{4})_\w+\.py$`)
+
+	// alembicRevision and alembicDownRevision match the revision identifiers
+	// at the top of an Alembic migration
+	alembicRevision     = regexp.MustCompile(`(?m)^revision\s*(?::[^=]+)?=\s*['"]([^'"]+)['"]`)
+	alembicDownRevision = regexp.MustCompile(`(?m)^down_revision\s*(?::[^=]+)?=\s*(.+)$`)
+	quotedString        = regexp.MustCompile(`['"]([^'"]+)['"]`)
+)
+
+// migrationDirs are directory names migrations are kept in. A migration's
+// package is named after the directory that holds one of them.
+var migrationDirs = map[string]bool{
+	"migrations": true,
+	"migration":  true,
+	"versions":   true,
+}
+
+// resolveSQL names the package after the owner of a migrations directory and
+// records the migration ordinal
+func resolveSQL(p *Processor, f *sourceFile) packageInfo {
+	m, ok := p.migrations.detect(f.abs)
+	if !ok {
+		return packageInfo{}
+	}
+
+	return packageInfo{
+		Package: migrationOwner(f.abs),
+		Fields:  map[string]string{annotator.KeyMigration: m.Ordinal},
+	}
+}
+
+// migrationOwner returns the name of the directory holding the migrations
+// directory of the file, such as the Django app, or "" when the file is not
+// in one of migrationDirs
+func migrationOwner(absPath string) string {
+	if dir := filepath.Dir(absPath); migrationDirs[filepath.Base(dir)] {
+		return filepath.Base(filepath.Dir(dir))
+	}
+	return ""
+}
+
+// migrations detects migration files and caches what detection needs to read
+type migrations struct {
+	mu      sync.Mutex
+	alembic map[string]map[string]int // versions directory -> file -> ordinal
+
+	trackedOnce sync.Once
+	tracked     map[string]bool // repository-relative paths git tracks (nil if unknown)
+}
+
+func newMigrations() *migrations {
+	return &migrations{alembic: make(map[string]map[string]int)}
+}
+
+// detect reports whether the file at absPath is a Flyway, golang-migrate,
+// goose, dbmate, Django or Alembic migration
+func (m *migrations) detect(absPath string) (migration, bool) {
+	base := filepath.Base(absPath)
+	dir := filepath.Dir(absPath)
+
+	if match := flywayMigration.FindStringSubmatch(base); match != nil {
+		return migration{Ordinal: strings.ReplaceAll(match[2], "_", "."), Flyway: true}, true
+	}
+	if match := versionedMigration.FindStringSubmatch(base); match != nil {
+		return migration{Ordinal: match[1]}, true
+	}
+
+	if filepath.Ext(base) != ".py" || base == "__init__.py" {
+		return migration{}, false
+	}
+	if match := djangoMigration.FindStringSubmatch(base); match != nil &&
+		filepath.Base(dir) == "migrations" && fileExists(filepath.Join(dir, "__init__.py")) {
+		return migration{Ordinal: match[1]}, true
+	}
+	if filepath.Base(dir) == "versions" && fileExists(filepath.Join(filepath.Dir(dir), "env.py")) {
+		if ordinal, ok := m.alembicOrdinals(dir)[base]; ok {
+			return migration{Ordinal: strconv.Itoa(ordinal)}, true
+		}
+	}
+	return migration{}, false
+}
+
+// alembicOrdinals numbers the migrations in an Alembic versions directory by
+// their depth in the revision graph, starting at 1 for the base revision
+func (m *migrations) alembicOrdinals(dir string) map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ordinals, ok := m.alembic[dir]; ok {
+		return ordinals
+	}
+
+	files := make(map[string]string)     // revision -> file
+	parents := make(map[string][]string) // revision -> down revisions
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".py" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		rev := alembicRevision.FindSubmatch(data)
+		if rev == nil {
+			continue
+		}
+		files[string(rev[1])] = entry.Name()
+		if down := alembicDownRevision.FindSubmatch(data); down != nil {
+			for _, q := range quotedString.FindAllSubmatch(down[1], -1) {
+				parents[string(rev[1])] = append(parents[string(rev[1])], string(q[1]))
+			}
+		}
+	}
+
+	depths := make(map[string]int)
+	var depth func(rev string, seen map[string]bool) int
+	depth = func(rev string, seen map[string]bool) int {
+		if d, ok := depths[rev]; ok {
+			return d
+		}
+		if seen[rev] {
+			return 0
+		}
+		seen[rev] = true
+		d := 1
+		for _, parent := range parents[rev] {
+			if _, ok := files[parent]; ok {
+				d = max(d, depth(parent, seen)+1)
+			}
+		}
+		depths[rev] = d
+		return d
+	}
+
+	ordinals := make(map[string]int, len(files))
+	for rev, file := range files {
+		ordinals[file] = depth(rev, map[string]bool{})
+	}
+	m.alembic[dir] = ordinals
+	return ordinals
+}
+
+// isTracked reports whether git tracks the file, and whether that could be
+// found out at all
+func (m *migrations) isTracked(root, absPath string) (tracked, known bool) {
+	m.trackedOnce.Do(func() {
+		out, err := exec.Command("git", "-C", root, "ls-files", "-z").Output()
+		if err != nil {
+			return
+		}
+		m.tracked = make(map[string]bool)
+		for _, name := range strings.Split(string(out), "\x00") {
+			if name != "" {
+				m.tracked[name] = true
+			}
+		}
+	})
+	if m.tracked == nil {
+		return false, false
+	}
+	return m.tracked[relSlash(root, absPath)], true
+}
+
+// isAppliedFlyway reports whether the file is a Flyway migration that has
+// been applied. Flyway checksums every migration it has applied, so a new
+// header on one breaks flyway validate, and on a repeatable migration makes
+// Flyway run it again. Migrations committed to git are taken to be applied;
+// when git cannot tell, every Flyway migration is.
+func (p *Processor) isAppliedFlyway(f *sourceFile) bool {
+	m, ok := p.migrations.detect(f.abs)
+	if !ok || !m.Flyway {
+		return false
+	}
+	tracked, known := p.migrations.isTracked(p.paths.gitRoot, f.abs)
+	return tracked || !known
+}
@@ -0,0 +1,160 @@
+package processor
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrationsDetect(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/migrations/__init__.py":       "",
+		"app/migrations/0001_initial.py":   "",
+		"app/migrations/helpers.py":        "",
+		"other/migrations/0001_initial.py": "",
+		"alembic/env.py":                   "",
+		"alembic/versions/abc_create.py":   "revision = 'abc'\ndown_revision = None\n",
+		"alembic/versions/notes.py":        "x = 1\n",
+		"scripts/versions/abc_create.py":   "revision = 'abc'\n",
+	})
+
+	tests := []struct {
+		path   string
+		want   migration
+		wantOK bool
+	}{
+		{"db/V1__init.sql", migration{Ordinal: "1", Flyway: true}, true},
+		{"db/V1_2_3__add_index.sql", migration{Ordinal: "1.2.3", Flyway: true}, true},
+		{"db/U2.1__undo.sql", migration{Ordinal: "2.1", Flyway: true}, true},
+		{"db/R__views.sql", migration{Flyway: true}, true},
+		{"db/V__missing_version.sql", migration{}, false},
+		{"db/V1_init.sql", migration{}, false},
+		{"db/000001_create_users.up.sql", migration{Ordinal: "000001"}, true},
+		{"db/000001_create_users.down.sql", migration{Ordinal: "000001"}, true},
+		{"db/20240101120000_add_orders.sql", migration{Ordinal: "20240101120000"}, true},
+		{"db/schema.sql", migration{}, false},
+		{"db/1_seed.data.sql", migration{}, false},
+		{"app/migrations/0001_initial.py", migration{Ordinal: "0001"}, true},
+		{"app/migrations/helpers.py", migration{}, false},
+		{"app/migrations/__init__.py", migration{}, false},
+		// A Django migrations directory is a package
+		{"other/migrations/0001_initial.py", migration{}, false},
+		{"alembic/versions/abc_create.py", migration{Ordinal: "1"}, true},
+		{"alembic/versions/notes.py", migration{}, false},
+		// An Alembic versions directory sits next to env.py
+		{"scripts/versions/abc_create.py", migration{}, false},
+	}
+
+	m := newMigrations()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := m.detect(filepath.Join(root, filepath.FromSlash(tt.path)))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("detect() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAlembicOrdinals(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "versions")
+	writeFiles(t, dir, map[string]string{
+		"a_base.py":     "revision = 'a'\ndown_revision = None\n",
+		"b_users.py":    "revision: str = \"b\"\ndown_revision: Union[str, None] = 'a'\n",
+		"c_orders.py":   "revision = 'c'\ndown_revision = 'b'\n",
+		"d_branch.py":   "revision = 'd'\ndown_revision = 'a'\n",
+		"e_merge.py":    "revision = 'e'\ndown_revision = ('c', 'd')\n",
+		"f_squashed.py": "revision = 'f'\ndown_revision = 'gone'\n",
+		"g_loop.py":     "revision = 'g'\ndown_revision = 'h'\n",
+		"h_loop.py":     "revision = 'h'\ndown_revision = 'g'\n",
+		"helpers.py":    "def helper():\n    revision = 'x'\n",
+		"README.txt":    "revision = 'r'\n",
+	})
+
+	m := newMigrations()
+	got := m.alembicOrdinals(dir)
+	want := map[string]int{
+		"a_base.py":     1,
+		"b_users.py":    2,
+		"c_orders.py":   3,
+		"d_branch.py":   2,
+		"e_merge.py":    4,
+		"f_squashed.py": 1,
+	}
+	for file, ordinal := range want {
+		if got[file] != ordinal {
+			t.Errorf("ordinal of %s = %d, want %d", file, got[file], ordinal)
+		}
+	}
+	if _, ok := got["helpers.py"]; ok {
+		t.Errorf("helpers.py has an ordinal")
+	}
+	// A cycle must not hang, whatever it numbers
+	for _, file := range []string{"g_loop.py", "h_loop.py"} {
+		if got[file] < 1 {
+			t.Errorf("ordinal of %s = %d, want at least 1", file, got[file])
+		}
+	}
+	if again := m.alembicOrdinals(dir); !reflect.DeepEqual(again, got) {
+		t.Errorf("second alembicOrdinals() = %v, want %v", again, got)
+	}
+}
+
+func TestIsAppliedFlyway(t *testing.T) {
+	files := map[string]string{
+		"db/V1__init.sql":    "",
+		"db/V2__new.sql":     "",
+		"db/R__views.sql":    "",
+		"db/000001_a.up.sql": "",
+		"db/query.sql":       "",
+	}
+
+	t.Run("git", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+		root := t.TempDir()
+		writeFiles(t, root, files)
+		for _, args := range [][]string{{"init", "-q"}, {"add", "db/V1__init.sql", "db/R__views.sql", "db/000001_a.up.sql"}} {
+			if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+
+		want := map[string]bool{
+			"db/V1__init.sql":    true,
+			"db/V2__new.sql":     false,
+			"db/R__views.sql":    true,
+			"db/000001_a.up.sql": false,
+			"db/query.sql":       false,
+		}
+		p := newTestProcessor(t, root)
+		for name, applied := range want {
+			if got := p.isAppliedFlyway(newSourceFile(filepath.Join(root, filepath.FromSlash(name)))); got != applied {
+				t.Errorf("isAppliedFlyway(%s) = %v, want %v", name, got, applied)
+			}
+		}
+	})
+
+	t.Run("no git", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, files)
+
+		// Without git to ask, every Flyway migration counts as applied
+		want := map[string]bool{
+			"db/V1__init.sql":    true,
+			"db/V2__new.sql":     true,
+			"db/R__views.sql":    true,
+			"db/000001_a.up.sql": false,
+			"db/query.sql":       false,
+		}
+		p := newTestProcessor(t, root)
+		for name, applied := range want {
+			if got := p.isAppliedFlyway(newSourceFile(filepath.Join(root, filepath.FromSlash(name)))); got != applied {
+				t.Errorf("isAppliedFlyway(%s) = %v, want %v", name, got, applied)
+			}
+		}
+	})
+}
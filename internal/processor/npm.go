@@ -0,0 +1,256 @@
+package processor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/glob"
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// npmPackages finds the package.json packages that JavaScript and TypeScript
+// files belong to, taking npm, yarn and pnpm workspaces into account
+type npmPackages struct {
+	mu         sync.Mutex
+	packages   map[string]*npmPackage   // directory -> enclosing package (nil if none)
+	manifests  map[string]*packageJSON  // package.json file -> parsed manifest (nil if unreadable)
+	workspaces map[string]*npmWorkspace // directory -> enclosing workspace (nil if none)
+}
+
+// npmPackage is a named package and the directory holding its package.json
+type npmPackage struct {
+	Name string
+	Dir  string
+}
+
+// npmWorkspace is a workspace root and the globs selecting its member packages
+type npmWorkspace struct {
+	dir     string
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+// packageJSON is the part of package.json codemap reads
+type packageJSON struct {
+	Name       string          `json:"name"`
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+func newNPMPackages() *npmPackages {
+	return &npmPackages{
+		packages:   make(map[string]*npmPackage),
+		manifests:  make(map[string]*packageJSON),
+		workspaces: make(map[string]*npmWorkspace),
+	}
+}
+
+// npmPackagePath is the pkgpath field for JavaScript and TypeScript files
+func npmPackagePath(pkg *npmPackage, absPath string) map[string]string {
+	return map[string]string{annotator.KeyPackagePath: relSlash(pkg.Dir, absPath)}
+}
+
+// resolveJavaScript names the package of the file after the nearest
+// package.json
+func resolveJavaScript(p *Processor, f *sourceFile) packageInfo {
+	pkg := p.npmPackages.find(filepath.Dir(f.abs))
+	if pkg == nil {
+		return packageInfo{}
+	}
+	return packageInfo{Package: pkg.Name, Fields: npmPackagePath(pkg, f.abs)}
+}
+
+// find returns the package containing dir: the nearest package.json with a
+// name. Inside a workspace only the root and its member packages count, so
+// package.json files of fixtures and examples are passed over.
+func (n *npmPackages) find(dir string) *npmPackage {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.findLocked(dir)
+}
+
+func (n *npmPackages) findLocked(dir string) *npmPackage {
+	if pkg, ok := n.packages[dir]; ok {
+		return pkg
+	}
+
+	var pkg *npmPackage
+	if m := n.manifest(filepath.Join(dir, "package.json")); m != nil && m.Name != "" && n.isMember(dir) {
+		pkg = &npmPackage{Name: m.Name, Dir: dir}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		pkg = n.findLocked(parent)
+	}
+	n.packages[dir] = pkg
+	return pkg
+}
+
+// isMember reports whether the package in dir is the root or a member of the
+// workspace it sits in, or is outside any workspace
+func (n *npmPackages) isMember(dir string) bool {
+	ws := n.workspace(dir)
+	if ws == nil || ws.dir == dir {
+		return true
+	}
+	rel := relSlash(ws.dir, dir)
+	for _, g := range ws.exclude {
+		if g.Match(rel) {
+			return false
+		}
+	}
+	for _, g := range ws.include {
+		if g.Match(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// workspace returns the outermost workspace root at or above dir
+func (n *npmPackages) workspace(dir string) *npmWorkspace {
+	if ws, ok := n.workspaces[dir]; ok {
+		return ws
+	}
+
+	var ws *npmWorkspace
+	if parent := filepath.Dir(dir); parent != dir {
+		ws = n.workspace(parent)
+	}
+	if ws == nil {
+		ws = n.loadWorkspace(dir)
+	}
+	n.workspaces[dir] = ws
+	return ws
+}
+
+// loadWorkspace reads the workspace globs of dir from pnpm-workspace.yaml or
+// the workspaces field of package.json
+func (n *npmPackages) loadWorkspace(dir string) *npmWorkspace {
+	patterns, ok := readPNPMWorkspace(filepath.Join(dir, "pnpm-workspace.yaml"))
+	if !ok {
+		m := n.manifest(filepath.Join(dir, "package.json"))
+		if m == nil || len(m.Workspaces) == 0 {
+			return nil
+		}
+		patterns = packageJSONWorkspaces(m.Workspaces)
+	}
+
+	ws := &npmWorkspace{dir: dir}
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./"), "/")
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			continue
+		}
+		if exclude {
+			ws.exclude = append(ws.exclude, g)
+		} else {
+			ws.include = append(ws.include, g)
+		}
+	}
+	return ws
+}
+
+// manifest parses a package.json file
+func (n *npmPackages) manifest(file string) *packageJSON {
+	if m, ok := n.manifests[file]; ok {
+		return m
+	}
+
+	var m *packageJSON
+	if data, err := os.ReadFile(file); err == nil {
+		var parsed packageJSON
+		if json.Unmarshal(data, &parsed) == nil {
+			m = &parsed
+		}
+	}
+	n.manifests[file] = m
+	return m
+}
+
+// packageJSONWorkspaces decodes the workspaces field, which is either a list
+// of globs or, with yarn, an object holding them under packages
+func packageJSONWorkspaces(raw json.RawMessage) []string {
+	var patterns []string
+	if err := json.Unmarshal(raw, &patterns); err == nil {
+		return patterns
+	}
+	var yarn struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(raw, &yarn); err == nil {
+		return yarn.Packages
+	}
+	return nil
+}
+
+// readPNPMWorkspace reads the packages list of a pnpm-workspace.yaml file. It
+// understands the block and flow list forms pnpm documents, not YAML in
+// general.
+func readPNPMWorkspace(file string) ([]string, bool) {
+	lines, err := readLines(file)
+	if err != nil {
+		return nil, false
+	}
+
+	var patterns []string
+	inPackages := false
+	for _, text := range lines {
+		line := stripYAMLComment(text)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		// A key at the start of the line ends the packages list
+		if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+			key, value, _ := strings.Cut(trimmed, ":")
+			inPackages = strings.TrimSpace(key) == "packages"
+			if value = strings.TrimSpace(value); inPackages && strings.HasPrefix(value, "[") {
+				for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+					if item = unquoteYAML(item); item != "" {
+						patterns = append(patterns, item)
+					}
+				}
+				inPackages = false
+			}
+			continue
+		}
+
+		if inPackages && strings.HasPrefix(trimmed, "-") {
+			if item := unquoteYAML(strings.TrimPrefix(trimmed, "-")); item != "" {
+				patterns = append(patterns, item)
+			}
+		}
+	}
+	return patterns, true
+}
+
+// stripYAMLComment drops a # comment that is not inside quotes
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquoteYAML trims a plain or quoted YAML scalar
+func unquoteYAML(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
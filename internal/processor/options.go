@@ -15,10 +15,20 @@ type Options struct {
 	Concurrent bool
 	// MaxWorkers limits the number of concurrent workers (0 = unlimited)
 	MaxWorkers int
-	// SupportedTypes lists the file extensions to process
+	// SupportedTypes lists the file extensions (".go") or language names
+	// ("dockerfile") to process. Empty means every registered language.
 	SupportedTypes []string
 	// Verbose enables detailed logging
 	Verbose bool
+	// PathMode selects how file paths are written into annotations
+	PathMode PathMode
+	// Fields lists the annotation fields to write, in order (see FieldNames)
+	Fields []string
+	// PreserveMtime keeps the modification time of files that are rewritten
+	PreserveMtime bool
+	// Force annotates files that are otherwise skipped because rewriting them
+	// could break a tool, such as applied Flyway migrations
+	Force bool
 }
 
 func DefaultOptions() Options {
@@ -50,18 +60,11 @@ func DefaultOptions() Options {
 			"*.sum",
 			"*.mod",
 		},
-		Concurrent: true,
-		MaxWorkers: 4,
-		SupportedTypes: []string{
-			".go",
-			".py",
-			".js",
-			".jsx",
-			".ts",
-			".tsx",
-			".dockerfile",
-			"",
-		},
-		Verbose: false,
+		Concurrent:     true,
+		MaxWorkers:     4,
+		SupportedTypes: nil,
+		Verbose:        false,
+		PathMode:       PathModeRelative,
+		Fields:         DefaultFields,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,116 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PathMode selects how file paths are written into annotations
+type PathMode string
+
+const (
+	// PathModeRelative writes paths relative to Options.Directory
+	PathModeRelative PathMode = "relative"
+	// PathModeRepo writes paths relative to the root of the git repository
+	PathModeRepo PathMode = "repo"
+	// PathModeAbsolute writes absolute paths
+	PathModeAbsolute PathMode = "absolute"
+	// PathModeImport writes the Go import path followed by the file name, and
+	// falls back to PathModeRepo for files without an import path
+	PathModeImport PathMode = "import"
+)
+
+// PathModes lists the supported path modes
+var PathModes = []PathMode{PathModeRelative, PathModeRepo, PathModeAbsolute, PathModeImport}
+
+// ParsePathMode converts a --path-mode value into a PathMode
+func ParsePathMode(s string) (PathMode, error) {
+	for _, mode := range PathModes {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid path mode %q (expected one of relative, repo, absolute, import)", s)
+}
+
+// paths computes the forms a file path can take in an annotation
+type paths struct {
+	dir     string // absolute Options.Directory
+	gitRoot string // root of the enclosing git repository, or dir
+}
+
+func newPaths(directory string) (*paths, error) {
+	dir, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", directory, err)
+	}
+
+	root := findGitRoot(dir)
+	if root == "" {
+		root = dir
+	}
+
+	return &paths{dir: dir, gitRoot: root}, nil
+}
+
+// format returns absPath as written under mode. importPath is the import path
+// of the file's package, if it has one.
+func (ps *paths) format(mode PathMode, absPath, importPath string) string {
+	switch mode {
+	case PathModeAbsolute:
+		return absPath
+	case PathModeRepo:
+		return relSlash(ps.gitRoot, absPath)
+	case PathModeImport:
+		if importPath == "" {
+			return relSlash(ps.gitRoot, absPath)
+		}
+		return path.Join(importPath, filepath.Base(absPath))
+	default:
+		return relSlash(ps.dir, absPath)
+	}
+}
+
+// aliases returns the forms absPath takes under every mode other than mode
+func (ps *paths) aliases(mode PathMode, absPath, importPath string) []string {
+	primary := ps.format(mode, absPath, importPath)
+
+	var aliases []string
+	seen := map[string]bool{primary: true}
+	for _, other := range PathModes {
+		alias := ps.format(other, absPath, importPath)
+		if !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// relSlash returns target relative to base using forward slashes, so headers
+// read the same on every platform
+func relSlash(base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(target)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// findGitRoot returns the nearest directory at or above dir containing .git,
+// which is a directory in a clone and a file in a worktree or submodule
+func findGitRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/krzko/codemap/internal/languages"
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// phpNamespace matches a namespace declaration, which may share its line
+// with the opening tag
+var phpNamespace = regexp.MustCompile(`(?m)^\s*(?:<\?php\s+)?namespace\s+([\w\\]+)\s*[;{]`)
+
+// resolvePHP takes the package from the namespace declaration and the module
+// from the name in the nearest composer.json
+func resolvePHP(p *Processor, f *sourceFile) packageInfo {
+	info := packageInfo{Fields: map[string]string{}}
+	if data, err := os.ReadFile(f.path); err == nil {
+		if m := phpNamespace.FindSubmatch(cStyleComment.ReplaceAll(data, nil)); m != nil {
+			info.Package = string(m[1])
+		}
+	}
+	if composer := p.manifests.nearest(filepath.Dir(f.abs), "composer.json"); composer != "" {
+		if data, err := os.ReadFile(composer); err == nil {
+			var manifest struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(data, &manifest) == nil {
+				info.Fields[annotator.KeyModule] = manifest.Name
+			}
+		}
+	}
+	return info
+}
+
+// phpOpensWithCode reports whether a PHP file opens a <?php tag on its first
+// line, after any shebang, so a // header below it is a comment rather than
+// output text
+func phpOpensWithCode(path string) bool {
+	lines, err := readLines(path)
+	if err != nil {
+		return false
+	}
+
+	for _, text := range lines {
+		line := strings.TrimPrefix(text, "\ufeff")
+		if strings.HasPrefix(line, "#!") {
+			continue
+		}
+		return languages.IsPHPOpenTag(line)
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+func TestResolvePHP(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"composer.json":                  `{"name": "acme/shop", "autoload": {"psr-4": {"Acme\\Shop\\": "src/"}}}`,
+		"src/Order.php":                  "<?php\n\ndeclare(strict_types=1);\n\nnamespace Acme\\Shop;\n\nfinal class Order {}\n",
+		"src/Legacy.php":                 "<?php\n// namespace Wrong;\n/*\nnamespace AlsoWrong;\n*/\nnamespace Acme\\Legacy {\n}\n",
+		"src/functions.php":              "<?php\nfunction helper() {}\n",
+		"packages/cart/composer.json":    `{"name": "acme/cart"}`,
+		"packages/cart/src/Cart.php":     "<?php namespace Acme\\Cart;\n",
+		"packages/broken/composer.json":  `{"name": `,
+		"packages/broken/src/Broken.php": "<?php\nnamespace Broken;\n",
+	})
+
+	tests := []struct {
+		path, pkg, module string
+	}{
+		{"src/Order.php", `Acme\Shop`, "acme/shop"},
+		{"src/Legacy.php", `Acme\Legacy`, "acme/shop"},
+		{"src/functions.php", "", "acme/shop"},
+		{"packages/cart/src/Cart.php", `Acme\Cart`, "acme/cart"},
+		{"packages/broken/src/Broken.php", "Broken", ""},
+	}
+
+	p := newTestProcessor(t, root, "path")
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info := resolvePHP(p, newSourceFile(filepath.Join(root, filepath.FromSlash(tt.path))))
+			if info.Package != tt.pkg || info.Fields[annotator.KeyModule] != tt.module {
+				t.Errorf("got package %q module %q, want %q %q", info.Package, info.Fields[annotator.KeyModule], tt.pkg, tt.module)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"sync"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// PlanApply computes the change Process would make to each supported file,
+// without writing anything. Changes are returned in walk order.
+func (p *Processor) PlanApply() ([]*annotator.Change, error) {
+	return p.plan(func(path string) (*annotator.Change, error) {
+		return p.annotator.PlanAnnotation(p.fileInfo(path))
+	})
+}
+
+// PlanClean computes the change Clean would make to each supported file,
+// without writing anything. Changes are returned in walk order.
+func (p *Processor) PlanClean() ([]*annotator.Change, error) {
+	return p.plan(func(path string) (*annotator.Change, error) {
+		return p.annotator.PlanRemoval(p.removalInfo(path))
+	})
+}
+
+func (p *Processor) plan(planFile func(path string) (*annotator.Change, error)) ([]*annotator.Change, error) {
+	files, err := p.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var supported []string
+	for _, file := range files {
+		if p.isSupported(file) {
+			supported = append(supported, file)
+		}
+	}
+
+	changes := make([]*annotator.Change, len(supported))
+	errs := make([]error, len(supported))
+
+	workers := 1
+	if p.opts.Concurrent && p.opts.MaxWorkers > 0 {
+		workers = p.opts.MaxWorkers
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, workers)
+	for i, file := range supported {
+		wg.Add(1)
+		go func(i int, f string) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Acquire
+			defer func() { <-semaphore }() // Release
+
+			changes[i], errs[i] = planFile(f)
+		}(i, file)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
@@ -8,14 +8,30 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/krzko/codemap/internal/languages"
 	"github.com/krzko/codemap/pkg/annotator"
 	"github.com/krzko/codemap/pkg/walker"
 )
 
 type Processor struct {
-	opts      Options
-	annotator annotator.Annotator
-	walker    *walker.Walker
+	opts        Options
+	annotator   annotator.Annotator
+	walker      *walker.Walker
+	goModules   *goModules
+	tsConfigs   *tsConfigs
+	npmPackages *npmPackages
+	pyProjects  *pythonProjects
+	cargoCrates *cargoCrates
+	jvmModules  *jvmModules
+	cTargets    *cTargets
+	makeTargets *makeTargets
+	migrations  *migrations
+	manifests   *manifests
+	paths       *paths
+	results     *results
+
+	ownersOnce sync.Once
+	owners     *codeOwners
 }
 
 type Stats struct {
@@ -25,6 +41,27 @@ type Stats struct {
 	FilesByLanguage  map[string]int
 }
 
+// results counts the outcome of AddAnnotation across files
+type results struct {
+	mu     sync.Mutex
+	counts map[annotator.Result]int
+}
+
+func (r *results) record(result annotator.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts == nil {
+		r.counts = make(map[annotator.Result]int)
+	}
+	r.counts[result]++
+}
+
+func (r *results) count(result annotator.Result) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[result]
+}
+
 // New creates a new Processor instance
 func New(opts Options) (*Processor, error) {
 	w, err := walker.New(
@@ -36,10 +73,34 @@ func New(opts Options) (*Processor, error) {
 		return nil, fmt.Errorf("failed to initialize walker: %w", err)
 	}
 
+	if len(opts.Fields) == 0 {
+		opts.Fields = DefaultFields
+	}
+	if err := validateFields(opts.Fields); err != nil {
+		return nil, err
+	}
+
+	ps, err := newPaths(opts.Directory)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Processor{
-		opts:      opts,
-		annotator: annotator.New(),
-		walker:    w,
+		opts:        opts,
+		annotator:   annotator.New(annotator.WithPreserveMtime(opts.PreserveMtime)),
+		walker:      w,
+		goModules:   newGoModules(),
+		tsConfigs:   newTSConfigs(),
+		npmPackages: newNPMPackages(),
+		pyProjects:  newPythonProjects(),
+		cargoCrates: newCargoCrates(),
+		jvmModules:  newJVMModules(),
+		cTargets:    newCTargets(),
+		makeTargets: newMakeTargets(),
+		migrations:  newMigrations(),
+		manifests:   newManifests(),
+		paths:       ps,
+		results:     &results{},
 	}, nil
 }
 
@@ -62,7 +123,7 @@ func (p *Processor) Clean() error {
 			continue
 		}
 
-		if err := p.annotator.RemoveAnnotation(file); err != nil {
+		if err := p.annotator.RemoveAnnotation(p.removalInfo(file)); err != nil {
 			if p.opts.Verbose {
 				log.Printf("Error cleaning %s: %v", file, err)
 			}
@@ -88,15 +149,14 @@ func (p *Processor) GetStats() (*Stats, error) {
 	}
 
 	for _, file := range files {
-		ext := filepath.Ext(file)
-		stats.FilesByLanguage[ext]++
+		stats.FilesByLanguage[p.determineLanguage(file)]++
 
 		content, err := os.ReadFile(file)
 		if err != nil {
 			return nil, err
 		}
 
-		if p.annotator.HasAnnotation(string(content)) {
+		if p.annotator.HasAnnotation(file, string(content)) {
 			stats.AnnotatedFiles++
 		} else {
 			stats.UnannotatedFiles++
@@ -106,6 +166,17 @@ func (p *Processor) GetStats() (*Stats, error) {
 	return stats, nil
 }
 
+// Directory returns the absolute path of the directory being processed
+func (p *Processor) Directory() string {
+	return p.paths.dir
+}
+
+// RepoRoot returns the root of the git repository containing Directory, or
+// Directory itself outside a repository
+func (p *Processor) RepoRoot() string {
+	return p.paths.gitRoot
+}
+
 // ListFiles returns a list of files that would be processed
 func (p *Processor) ListFiles() ([]string, error) {
 	return p.walker.Walk()
@@ -137,13 +208,23 @@ func (p *Processor) Process() error {
 		log.Printf("Running in add mode - adding annotations")
 	}
 
+	p.results = &results{}
 	if p.opts.Concurrent {
 		log.Printf("Processing files concurrently with %d workers", p.opts.MaxWorkers)
-		return p.processConcurrent(supportedFiles)
+		err = p.processConcurrent(supportedFiles)
+	} else {
+		log.Printf("Processing files sequentially")
+		err = p.processSequential(supportedFiles)
 	}
 
-	log.Printf("Processing files sequentially")
-	return p.processSequential(supportedFiles)
+	if !p.opts.Clean {
+		log.Printf("%d up to date, %d refreshed, %d added, %d skipped",
+			p.results.count(annotator.UpToDate),
+			p.results.count(annotator.Refreshed),
+			p.results.count(annotator.Added),
+			p.results.count(annotator.Skipped))
+	}
+	return err
 }
 
 func (p *Processor) processConcurrent(files []string) error {
@@ -199,109 +280,91 @@ func (p *Processor) processFile(path string) error {
 
 	if p.opts.Clean {
 		log.Printf("Cleaning annotations from: %s", relPath)
-		return p.annotator.RemoveAnnotation(path)
+		return p.annotator.RemoveAnnotation(p.removalInfo(path))
 	}
 
 	log.Printf("Adding annotations to: %s", relPath)
-	info := annotator.FileInfo{
-		Path:        path,
-		Language:    p.determineLanguage(path),
-		ImportPath:  p.determineImportPath(path),
-		PackageName: p.determinePackageName(path),
-	}
-
-	err = p.annotator.AddAnnotation(info)
+	result, err := p.annotator.AddAnnotation(p.fileInfo(path))
 	if err != nil {
 		log.Printf("Error processing %s: %v", relPath, err)
 		return err
 	}
+	p.results.record(result)
 
 	return nil
 }
 
-func (p *Processor) determineLanguage(path string) string {
-	if filepath.Base(path) == "Dockerfile" {
-		return "Dockerfile"
-	}
+// fileInfo computes the annotation fields for the file at path, running the
+// providers selected by Options.Fields in order
+func (p *Processor) fileInfo(path string) annotator.FileInfo {
+	f := newSourceFile(path)
 
-	ext := filepath.Ext(path)
-	switch ext {
-	case ".go":
-		return "Go"
-	case ".py":
-		return "Python"
-	case ".js", ".jsx":
-		return "JavaScript"
-	case ".ts", ".tsx":
-		return "TypeScript"
-	case ".dockerfile":
-		return "Dockerfile"
-	default:
-		return "Unknown"
+	info := annotator.FileInfo{Path: path}
+	for _, name := range p.opts.Fields {
+		if value, ok := fieldProviders[name](p, f); ok {
+			info.Fields = append(info.Fields, annotator.Field{Key: name, Value: value})
+		}
 	}
+	info.PathAliases = p.paths.aliases(p.opts.PathMode, f.abs, f.pkg(p).ImportDir)
+	info.SkipReason = p.skipReason(f)
+
+	return info
 }
 
-func (p *Processor) determineImportPath(path string) string {
-	// This is a simplistic implementation - you might want to make it more sophisticated
-	// based on your project's structure
-	abs, err := filepath.Abs(path)
-	if err != nil {
-		return path
+// skipReason explains why the file must be left alone, or returns "".
+// Options.Force overrides the reasons that only protect tool checksums.
+func (p *Processor) skipReason(f *sourceFile) string {
+	if f.langName() == "PHP" && !phpOpensWithCode(f.path) {
+		return "no <?php opening tag on the first line"
 	}
-
-	// For Go files, try to determine the module path
-	if filepath.Ext(path) == ".go" {
-		// You might want to read go.mod file to get the module name
-		return p.determineGoImportPath(abs)
+	if !p.opts.Force && p.isAppliedFlyway(f) {
+		return "applied Flyway migration, use --force to annotate"
 	}
-
-	return abs
+	return ""
 }
 
-func (p *Processor) determineGoImportPath(absPath string) string {
-	// This is a placeholder - you should implement proper Go module detection
-	// by reading go.mod file and determining the module path
-	return absPath
+// removalInfo describes the file at path for removing its header. Only the
+// checksum protection applies, so headers can still be removed from files
+// that would no longer get one.
+func (p *Processor) removalInfo(path string) annotator.FileInfo {
+	info := annotator.FileInfo{Path: path}
+	if !p.opts.Force && p.isAppliedFlyway(newSourceFile(path)) {
+		info.SkipReason = "applied Flyway migration, use --force to clean"
+	}
+	return info
 }
 
-func (p *Processor) determinePackageName(path string) string {
-	// For Go files, read the package name from the file
-	if filepath.Ext(path) == ".go" {
-		return p.readGoPackageName(path)
-	}
+// codeOwners loads the repository's CODEOWNERS on first use
+func (p *Processor) codeOwners() *codeOwners {
+	p.ownersOnce.Do(func() {
+		p.owners = loadCodeOwners(p.paths.gitRoot)
+	})
+	return p.owners
+}
 
-	// For Dockerfile, use "docker" as package name
-	if filepath.Base(path) == "Dockerfile" || filepath.Ext(path) == ".dockerfile" {
-		return "docker"
+func (p *Processor) determineLanguage(path string) string {
+	if lang, ok := languages.ForPath(path); ok {
+		return lang.Name()
 	}
-
-	// For other files, use the directory name as package name
-	return filepath.Base(filepath.Dir(path))
+	return "Unknown"
 }
 
+// isSupported reports whether a registered language handles the file and it
+// passes the SupportedTypes filter, which matches extensions or language names
 func (p *Processor) isSupported(path string) bool {
-	ext := filepath.Ext(path)
+	lang, ok := languages.ForPath(path)
+	if !ok {
+		return false
+	}
+	if len(p.opts.SupportedTypes) == 0 {
+		return true
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
 	for _, supported := range p.opts.SupportedTypes {
-		if ext == supported {
+		if (ext != "" && ext == supported) || strings.EqualFold(strings.TrimPrefix(supported, "."), lang.Name()) {
 			return true
 		}
 	}
 	return false
 }
-
-func (p *Processor) readGoPackageName(path string) string {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return "unknown"
-	}
-
-	// Simple package name extraction - you might want to make this more robust
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), "package ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "package "))
-		}
-	}
-
-	return "unknown"
-}
\ No newline at end of file
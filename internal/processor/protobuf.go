@@ -0,0 +1,42 @@
+package processor
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+var (
+	// protoPackage matches the package statement of a .proto file
+	protoPackage = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	// protoOption matches a file option with a string value
+	protoOption = regexp.MustCompile(`(?m)^\s*option\s+(\w+)\s*=\s*"([^"]*)"\s*;`)
+)
+
+// resolveProtobuf takes the package from the package statement and records
+// the packages the go_package and java_package options generate code into
+func resolveProtobuf(p *Processor, f *sourceFile) packageInfo {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return packageInfo{}
+	}
+	data = cStyleComment.ReplaceAll(data, nil)
+
+	info := packageInfo{Fields: map[string]string{}}
+	if m := protoPackage.FindSubmatch(data); m != nil {
+		info.Package = string(m[1])
+	}
+	for _, m := range protoOption.FindAllSubmatch(data, -1) {
+		switch value := string(m[2]); string(m[1]) {
+		case annotator.KeyGoPackage:
+			// "example.com/foo/bar;barpb" names the Go package after the
+			// import path
+			info.Fields[annotator.KeyGoPackage], _, _ = strings.Cut(value, ";")
+		case annotator.KeyJavaPackage:
+			info.Fields[annotator.KeyJavaPackage] = value
+		}
+	}
+	return info
+}
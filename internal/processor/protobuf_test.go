@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+func TestResolveProtobuf(t *testing.T) {
+	tests := []struct {
+		name   string
+		proto  string
+		pkg    string
+		fields map[string]string
+	}{
+		{
+			name: "options",
+			proto: `syntax = "proto3";
+
+package acme.billing.v1;
+
+option go_package = "github.com/acme/api/gen/billing/v1;billingv1";
+option java_package = "com.acme.billing.v1";
+option java_multiple_files = true;
+option csharp_namespace = "Acme.Billing.V1";
+`,
+			pkg: "acme.billing.v1",
+			fields: map[string]string{
+				annotator.KeyGoPackage:   "github.com/acme/api/gen/billing/v1",
+				annotator.KeyJavaPackage: "com.acme.billing.v1",
+			},
+		},
+		{
+			name:   "go package without name",
+			proto:  "syntax = \"proto3\";\npackage orders;\noption go_package = \"example.com/orders\";\n",
+			pkg:    "orders",
+			fields: map[string]string{annotator.KeyGoPackage: "example.com/orders"},
+		},
+		{
+			name: "comments",
+			proto: `// package wrong;
+/*
+package also.wrong;
+option go_package = "example.com/wrong";
+*/
+syntax = "proto2";
+  package   spaced.pkg ;  // trailing
+message M {
+  // option java_package = "com.wrong";
+  optional string name = 1;
+}
+`,
+			pkg:    "spaced.pkg",
+			fields: map[string]string{},
+		},
+		{
+			name:   "no package",
+			proto:  "syntax = \"proto3\";\nmessage Empty {}\n",
+			fields: map[string]string{},
+		},
+		{
+			name:   "field option",
+			proto:  "package a;\nmessage M {\n  string s = 1 [json_name = \"s\"];\n}\n",
+			pkg:    "a",
+			fields: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"api.proto": tt.proto})
+			p := newTestProcessor(t, dir, "path")
+
+			got := resolveProtobuf(p, newSourceFile(filepath.Join(dir, "api.proto")))
+			if got.Package != tt.pkg {
+				t.Errorf("Package = %q, want %q", got.Package, tt.pkg)
+			}
+			if got.Import != "" {
+				t.Errorf("Import = %q, want none", got.Import)
+			}
+			if !reflect.DeepEqual(got.Fields, tt.fields) {
+				t.Errorf("Fields = %v, want %v", got.Fields, tt.fields)
+			}
+		})
+	}
+
+	if got := resolveProtobuf(nil, newSourceFile(filepath.Join(t.TempDir(), "missing.proto"))); !reflect.DeepEqual(got, packageInfo{}) {
+		t.Errorf("resolveProtobuf() of a missing file = %+v", got)
+	}
+}
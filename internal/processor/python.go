@@ -0,0 +1,240 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// pythonProjectFiles mark the root of a Python project, in the order their
+// metadata is read
+var pythonProjectFiles = []string{"pyproject.toml", "setup.cfg", "setup.py"}
+
+// pythonProjects finds the Python projects files belong to and the
+// directories their packages are imported from
+type pythonProjects struct {
+	mu       sync.Mutex
+	roots    map[string]string         // directory -> enclosing project root ("" if none)
+	projects map[string]*pythonProject // project root -> project metadata
+}
+
+// pythonProject is the metadata codemap reads from a project's build files
+type pythonProject struct {
+	dir         string
+	name        string   // distribution name, "" if not declared
+	packageDirs []string // absolute directories packages are found in
+}
+
+func newPythonProjects() *pythonProjects {
+	return &pythonProjects{
+		roots:    make(map[string]string),
+		projects: make(map[string]*pythonProject),
+	}
+}
+
+// resolvePython works out the dotted module path of the file and names its
+// package after the project's distribution. Django and Alembic migrations get
+// their ordinal too.
+func resolvePython(p *Processor, f *sourceFile) packageInfo {
+	project := p.pyProjects.find(filepath.Dir(f.abs))
+
+	info := packageInfo{Import: pythonModule(project, f.abs)}
+	switch {
+	case project != nil && project.name != "":
+		info.Package = project.name
+	case filepath.Base(f.abs) == "__init__.py":
+		info.Package = info.Import
+	case strings.Contains(info.Import, "."):
+		info.Package = info.Import[:strings.LastIndex(info.Import, ".")]
+	}
+	if m, ok := p.migrations.detect(f.abs); ok {
+		info.Fields = map[string]string{annotator.KeyMigration: m.Ordinal}
+		if info.Package == "" {
+			info.Package = migrationOwner(f.abs)
+		}
+	}
+	return info
+}
+
+// pythonModule returns the dotted module path of the Python file at absPath,
+// or "" when it cannot be imported
+func pythonModule(project *pythonProject, absPath string) string {
+	root := pythonImportRoot(project, absPath)
+	if root == "" {
+		return ""
+	}
+
+	rel := strings.TrimSuffix(relSlash(root, absPath), ".py")
+	parts := strings.Split(rel, "/")
+	if parts[len(parts)-1] == "__init__" {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	// Test suites are run by a test runner, and build and test configuration
+	// files at the project root by their tools, rather than imported
+	if project != nil {
+		projectRel := relSlash(project.dir, absPath)
+		if isPythonTestDir(projectRel) || pythonToolFiles[projectRel] {
+			return ""
+		}
+	}
+	for _, part := range parts {
+		if !identifier.MatchString(part) {
+			return ""
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// pythonImportRoot returns the directory a file's module path is relative to.
+// Inside a project that is the configured package directory, src/ or the
+// project root, which also covers namespace packages without __init__.py.
+// Outside a project it is the parent of the outermost __init__.py package.
+func pythonImportRoot(project *pythonProject, absPath string) string {
+	if project != nil {
+		for _, dir := range project.packageDirs {
+			if isWithin(dir, absPath) {
+				return dir
+			}
+		}
+		return ""
+	}
+
+	root := ""
+	for dir := filepath.Dir(absPath); fileExists(filepath.Join(dir, "__init__.py")); dir = filepath.Dir(dir) {
+		root = filepath.Dir(dir)
+		if root == dir {
+			break
+		}
+	}
+	return root
+}
+
+// pythonToolFiles are files at a project root that tools run rather than
+// code imports
+var pythonToolFiles = map[string]bool{
+	"setup.py":    true,
+	"conftest.py": true,
+	"noxfile.py":  true,
+	"fabfile.py":  true,
+}
+
+// isPythonTestDir reports whether a project-relative path is in the tests/ or
+// test/ directory at the top of the project
+func isPythonTestDir(rel string) bool {
+	first, _, nested := strings.Cut(rel, "/")
+	return nested && (first == "tests" || first == "test")
+}
+
+// find returns the project containing dir, or nil
+func (pp *pythonProjects) find(dir string) *pythonProject {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	root := pp.projectRoot(dir)
+	if root == "" {
+		return nil
+	}
+	if project, ok := pp.projects[root]; ok {
+		return project
+	}
+	project := readPythonProject(root)
+	pp.projects[root] = project
+	return project
+}
+
+// projectRoot returns the nearest directory at or above dir holding one of
+// pythonProjectFiles
+func (pp *pythonProjects) projectRoot(dir string) string {
+	if root, ok := pp.roots[dir]; ok {
+		return root
+	}
+
+	root := ""
+	for _, name := range pythonProjectFiles {
+		if fileExists(filepath.Join(dir, name)) {
+			root = dir
+			break
+		}
+	}
+	if root == "" {
+		if parent := filepath.Dir(dir); parent != dir {
+			root = pp.projectRoot(parent)
+		}
+	}
+	pp.roots[dir] = root
+	return root
+}
+
+// readPythonProject reads the distribution name and package directories from
+// pyproject.toml, setup.cfg and setup.py, taking the first that declares each
+func readPythonProject(dir string) *pythonProject {
+	project := &pythonProject{dir: dir}
+	var packageDirs []string
+
+	readConfigSections(filepath.Join(dir, "pyproject.toml"), false, func(section, key, value string) {
+		switch {
+		case key == "name" && (section == "project" || section == "tool.poetry"):
+			if project.name == "" {
+				project.name = unquoteTOML(value)
+			}
+		case section == "tool.setuptools.package-dir" && unquoteTOML(key) == "":
+			packageDirs = append(packageDirs, unquoteTOML(value))
+		case section == "tool.setuptools.packages.find" && key == "where":
+			packageDirs = append(packageDirs, tomlStrings(value)...)
+		}
+	})
+
+	readConfigSections(filepath.Join(dir, "setup.cfg"), true, func(section, key, value string) {
+		switch {
+		case section == "metadata" && key == "name":
+			if project.name == "" {
+				project.name = value
+			}
+		case section == "options" && key == "package_dir":
+			// Lines of "package = dir", where the empty package is the root
+			for _, line := range strings.Split(value, "\n") {
+				if pkg, pkgDir, ok := strings.Cut(line, "="); ok && strings.TrimSpace(pkg) == "" {
+					packageDirs = append(packageDirs, strings.TrimSpace(pkgDir))
+				}
+			}
+		case section == "options.packages.find" && key == "where":
+			packageDirs = append(packageDirs, value)
+		}
+	})
+
+	if project.name == "" {
+		if data, err := os.ReadFile(filepath.Join(dir, "setup.py")); err == nil {
+			if m := setupPyName.FindSubmatch(data); m != nil {
+				project.name = string(m[1])
+			}
+		}
+	}
+
+	for _, d := range packageDirs {
+		if d = strings.TrimSpace(d); d != "" && d != "." {
+			project.packageDirs = append(project.packageDirs, filepath.Join(dir, filepath.FromSlash(d)))
+		}
+	}
+	if src := filepath.Join(dir, "src"); len(project.packageDirs) == 0 && isDir(src) {
+		project.packageDirs = append(project.packageDirs, src)
+	}
+	project.packageDirs = append(project.packageDirs, dir)
+	return project
+}
+
+// setupPyName matches the name argument of a setup() call
+var setupPyName = regexp.MustCompile(`\bname\s*=\s*["']([^"']+)["']`)
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,184 @@
+package processor
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadPythonProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantName    string
+		packageDirs []string // relative to the project
+	}{
+		{
+			name:        "pyproject",
+			files:       map[string]string{"pyproject.toml": "[project]\nname = \"acme-billing\"\n"},
+			wantName:    "acme-billing",
+			packageDirs: []string{"."},
+		},
+		{
+			name:        "poetry",
+			files:       map[string]string{"pyproject.toml": "[tool.poetry]\nname = 'acme'\n"},
+			wantName:    "acme",
+			packageDirs: []string{"."},
+		},
+		{
+			name: "src layout",
+			files: map[string]string{
+				"pyproject.toml":       "[project]\nname = \"acme\"\n",
+				"src/acme/__init__.py": "",
+			},
+			wantName:    "acme",
+			packageDirs: []string{"src", "."},
+		},
+		{
+			name: "setuptools package-dir",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"acme\"\n\n[tool.setuptools.package-dir]\n\"\" = \"lib\"\n",
+				"src/x.py":       "",
+			},
+			wantName:    "acme",
+			packageDirs: []string{"lib", "."},
+		},
+		{
+			name:        "setuptools find where",
+			files:       map[string]string{"pyproject.toml": "[tool.setuptools.packages.find]\nwhere = [\"pkgs\", \"more\"] # both\n"},
+			packageDirs: []string{"pkgs", "more", "."},
+		},
+		{
+			name:        "setup.cfg",
+			files:       map[string]string{"setup.cfg": "[metadata]\nname = acme-cfg\n\n[options]\npackage_dir =\n    = lib\n    other = elsewhere\n"},
+			wantName:    "acme-cfg",
+			packageDirs: []string{"lib", "."},
+		},
+		{
+			name:        "setup.cfg find where",
+			files:       map[string]string{"setup.cfg": "[options.packages.find]\nwhere = source\n"},
+			packageDirs: []string{"source", "."},
+		},
+		{
+			name:        "setup.py",
+			files:       map[string]string{"setup.py": "from setuptools import setup\nsetup(\n    name='acme-py',\n    version='1.0',\n)\n"},
+			wantName:    "acme-py",
+			packageDirs: []string{"."},
+		},
+		{
+			name: "pyproject name wins",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"first\"\n",
+				"setup.cfg":      "[metadata]\nname = second\n",
+				"setup.py":       "setup(name=\"third\")\n",
+			},
+			wantName:    "first",
+			packageDirs: []string{"."},
+		},
+		{
+			name:        "other section name",
+			files:       map[string]string{"pyproject.toml": "[tool.black]\nname = \"not-the-project\"\n"},
+			packageDirs: []string{"."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			project := readPythonProject(dir)
+			if project.name != tt.wantName {
+				t.Errorf("name = %q, want %q", project.name, tt.wantName)
+			}
+			var dirs []string
+			for _, d := range project.packageDirs {
+				dirs = append(dirs, relSlash(dir, d))
+			}
+			if !reflect.DeepEqual(dirs, tt.packageDirs) {
+				t.Errorf("packageDirs = %q, want %q", dirs, tt.packageDirs)
+			}
+		})
+	}
+}
+
+func TestPythonModule(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/pyproject.toml":               "[project]\nname = \"acme\"\n",
+		"app/src/acme/__init__.py":         "",
+		"app/src/acme/billing/invoice.py":  "",
+		"app/src/acme/billing/__init__.py": "",
+		"app/src/acme/ns/plain.py":         "",
+		"app/src/acme/bad-name/x.py":       "",
+		"app/tests/test_invoice.py":        "",
+		"app/setup.py":                     "",
+		"app/conftest.py":                  "",
+		"app/scripts/run.py":               "",
+		"flat/setup.cfg":                   "[metadata]\nname = flat\n",
+		"flat/flat/core.py":                "",
+		"flat/flat/test/helpers.py":        "",
+		"loose/outer/__init__.py":          "",
+		"loose/outer/inner/__init__.py":    "",
+		"loose/outer/inner/mod.py":         "",
+		"loose/script.py":                  "",
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"app/src/acme/__init__.py", "acme"},
+		{"app/src/acme/billing/invoice.py", "acme.billing.invoice"},
+		{"app/src/acme/billing/__init__.py", "acme.billing"},
+		{"app/src/acme/ns/plain.py", "acme.ns.plain"},
+		{"app/src/acme/bad-name/x.py", ""},
+		{"app/tests/test_invoice.py", ""},
+		{"app/setup.py", ""},
+		{"app/conftest.py", ""},
+		{"app/scripts/run.py", "scripts.run"},
+		{"flat/flat/core.py", "flat.core"},
+		{"flat/flat/test/helpers.py", "flat.test.helpers"},
+		{"loose/outer/inner/mod.py", "outer.inner.mod"},
+		{"loose/outer/__init__.py", "outer"},
+		{"loose/script.py", ""},
+	}
+
+	pp := newPythonProjects()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			abs := filepath.Join(root, filepath.FromSlash(tt.path))
+			if got := pythonModule(pp.find(filepath.Dir(abs)), abs); got != tt.want {
+				t.Errorf("pythonModule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePython(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"named/pyproject.toml":    "[project]\nname = \"acme\"\n",
+		"named/acme/core.py":      "",
+		"loose/outer/__init__.py": "",
+		"loose/outer/inner.py":    "",
+	})
+
+	tests := []struct {
+		path, pkg, imp string
+	}{
+		{"named/acme/core.py", "acme", "acme.core"},
+		{"loose/outer/inner.py", "outer", "outer.inner"},
+		{"loose/outer/__init__.py", "outer", "outer"},
+	}
+
+	p := newTestProcessor(t, root, "path")
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info := resolvePython(p, newSourceFile(filepath.Join(root, filepath.FromSlash(tt.path))))
+			if info.Package != tt.pkg || info.Import != tt.imp {
+				t.Errorf("resolvePython() = pkg %q import %q, want %q %q", info.Package, info.Import, tt.pkg, tt.imp)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package processor
+
+import (
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+// packageInfo is what a resolver learns about the package a file belongs to
+type packageInfo struct {
+	// Package is the pkg field. Empty falls back to the directory name.
+	Package string
+	// Import is how other code imports the file or its package
+	Import string
+	// ImportDir is the import path of the file's directory, which
+	// PathModeImport joins with the file name. Empty for languages that do
+	// not import by directory.
+	ImportDir string
+	// Fields holds language-specific fields keyed by field name
+	Fields map[string]string
+}
+
+// resolver works out package information for the files of one language
+type resolver func(p *Processor, f *sourceFile) packageInfo
+
+// resolvers maps language names to their resolver. Languages without one get
+// the directory name as their package.
+var resolvers = map[string]resolver{
+	"C":          resolveC,
+	"C#":         resolveCSharp,
+	"C++":        resolveC,
+	"Dockerfile": resolveDockerfile,
+	"Go":         resolveGo,
+	"HCL":        resolveTerraform,
+	"Helm":       resolveHelm,
+	"Java":       resolveJava,
+	"JavaScript": resolveJavaScript,
+	"Kotlin":     resolveKotlin,
+	"PHP":        resolvePHP,
+	"Protobuf":   resolveProtobuf,
+	"Python":     resolvePython,
+	"Ruby":       resolveRuby,
+	"Rust":       resolveRust,
+	"SQL":        resolveSQL,
+	"Shell":      resolveShell,
+	"Terraform":  resolveTerraform,
+	"TypeScript": resolveTypeScript,
+	"YAML":       resolveYAML,
+}
+
+// resolveGo reads the package clause and build constraints from the file and
+// the import path from go.mod
+func resolveGo(p *Processor, f *sourceFile) packageInfo {
+	gf := readGoFile(f.path)
+	importPath := p.goModules.importPath(f.abs)
+
+	info := packageInfo{
+		Package:   gf.Package,
+		Import:    importPath,
+		ImportDir: importPath,
+		Fields:    map[string]string{},
+	}
+	if gf.BuildConstraint != "" {
+		info.Fields[annotator.KeyBuild] = gf.BuildConstraint
+	}
+	if gf.ExternalTest {
+		info.Fields[annotator.KeyTest] = "external"
+	}
+	return info
+}
+
+// resolveDockerfile uses "docker" as the package of every Dockerfile
+func resolveDockerfile(p *Processor, f *sourceFile) packageInfo {
+	return packageInfo{Package: "docker"}
+}
+
+// dirPackage is the fallback package name: the name of the file's directory
+func dirPackage(path string) string {
+	return filepath.Base(filepath.Dir(path))
+}
+
+// identifier matches the names Python and Rust allow for modules
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// manifests finds the nearest build manifest matching a glob, such as
+// "*.csproj", for languages whose project files are not named alike
+type manifests struct {
+	mu    sync.Mutex
+	found map[string]string // glob + "\x00" + directory -> manifest ("" if none)
+}
+
+func newManifests() *manifests {
+	return &manifests{found: make(map[string]string)}
+}
+
+// nearest returns the first file matching pattern in dir or the closest
+// ancestor that has one, or ""
+func (m *manifests) nearest(dir, pattern string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nearestLocked(dir, pattern)
+}
+
+func (m *manifests) nearestLocked(dir, pattern string) string {
+	key := pattern + "\x00" + dir
+	if file, ok := m.found[key]; ok {
+		return file
+	}
+
+	var file string
+	if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+		file = matches[0]
+	} else if parent := filepath.Dir(dir); parent != dir {
+		file = m.nearestLocked(parent, pattern)
+	}
+	m.found[key] = file
+	return file
+}
@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+var (
+	// rubyModule matches a module declaration, which may be a nested path
+	// such as Acme::Billing
+	rubyModule = regexp.MustCompile(`^module\s+((?:::)?[A-Z]\w*(?:::[A-Z]\w*)*)\s*(?:#.*)?$`)
+	// gemspecName matches the name assignment of a gem specification
+	gemspecName = regexp.MustCompile(`\.name\s*=\s*["']([^"']+)["']`)
+)
+
+// resolveRuby takes the package from the modules the file opens before its
+// first other statement, such as Acme::Billing, and the module from the gem
+// name in the nearest .gemspec
+func resolveRuby(p *Processor, f *sourceFile) packageInfo {
+	info := packageInfo{Package: rubyModuleNesting(f.path), Fields: map[string]string{}}
+	if gemspec := p.manifests.nearest(filepath.Dir(f.abs), "*.gemspec"); gemspec != "" {
+		if data, err := os.ReadFile(gemspec); err == nil {
+			if m := gemspecName.FindSubmatch(data); m != nil {
+				info.Fields[annotator.KeyModule] = string(m[1])
+			}
+		}
+	}
+	return info
+}
+
+// rubyModuleNesting joins the module declarations at the top of a file,
+// skipping comments and require lines, and stops at the first other line
+func rubyModuleNesting(path string) string {
+	lines, err := readLines(path)
+	if err != nil {
+		return ""
+	}
+
+	var modules []string
+	for _, text := range lines {
+		line := strings.TrimSpace(text)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "require"):
+			continue
+		case rubyModule.MatchString(line):
+			name := rubyModule.FindStringSubmatch(line)[1]
+			modules = append(modules, strings.TrimPrefix(name, "::"))
+			continue
+		}
+		break
+	}
+	return strings.Join(modules, "::")
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/krzko/codemap/pkg/annotator"
+)
+
+func TestRubyModuleNesting(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"single", "module Acme\n  class Invoice; end\nend\n", "Acme"},
+		{"nested", "# frozen_string_literal: true\n\nrequire \"json\"\nrequire_relative \"base\"\n\nmodule Acme\n  module Billing # payments\n    class Invoice\n", "Acme::Billing"},
+		{"compact path", "module Acme::Billing\n  module V2\n    def self.call; end\n", "Acme::Billing::V2"},
+		{"top-level constant", "module ::Acme\nend\n", "Acme"},
+		{"stops at code", "module Acme\n  VERSION = \"1.0\"\n  module Later\n  end\nend\n", "Acme"},
+		{"class first", "class Invoice\n  module Helpers\n", ""},
+		{"lowercase", "module acme\n", ""},
+		{"one-liner", "module Acme; end\n", ""},
+		{"crlf", "module Acme\r\n  module Billing\r\n", "Acme::Billing"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"a.rb": tt.content})
+			if got := rubyModuleNesting(filepath.Join(dir, "a.rb")); got != tt.want {
+				t.Errorf("rubyModuleNesting() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRuby(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"acme.gemspec":          "Gem::Specification.new do |spec|\n  spec.name    = \"acme-billing\"\n  spec.version = Acme::VERSION\nend\n",
+		"lib/acme/billing.rb":   "module Acme\n  module Billing\n  end\nend\n",
+		"vendor/tool/tool.rb":   "module Tool\nend\n",
+		"vendor/tool/t.gemspec": "Gem::Specification.new { |s| s.name = 'tool' }\n",
+	})
+
+	tests := []struct {
+		path, pkg, module string
+	}{
+		{"lib/acme/billing.rb", "Acme::Billing", "acme-billing"},
+		{"vendor/tool/tool.rb", "Tool", "tool"},
+	}
+
+	p := newTestProcessor(t, root, "path")
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info := resolveRuby(p, newSourceFile(filepath.Join(root, filepath.FromSlash(tt.path))))
+			if info.Package != tt.pkg || info.Fields[annotator.KeyModule] != tt.module {
+				t.Errorf("got package %q module %q, want %q %q", info.Package, info.Fields[annotator.KeyModule], tt.pkg, tt.module)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// cargoCrates finds the Cargo packages Rust files belong to. Workspace
+// manifests without a [package] table are passed over, so files resolve to
+// the member crate that holds them.
+type cargoCrates struct {
+	mu     sync.Mutex
+	crates map[string]*cargoCrate // directory -> enclosing crate (nil if none)
+}
+
+// cargoCrate is a Cargo package and the directory holding its Cargo.toml
+type cargoCrate struct {
+	Name string // crate name as written in code, with "-" replaced by "_"
+	Dir  string
+}
+
+func newCargoCrates() *cargoCrates {
+	return &cargoCrates{crates: make(map[string]*cargoCrate)}
+}
+
+// resolveRust names the package after the owning crate and works out the
+// module path from the file layout, so src/foo/bar.rs is crate::foo::bar
+func resolveRust(p *Processor, f *sourceFile) packageInfo {
+	crate := p.cargoCrates.find(filepath.Dir(f.abs))
+	if crate == nil {
+		return packageInfo{}
+	}
+	return packageInfo{Package: crate.Name, Import: rustModule(relSlash(crate.Dir, f.abs))}
+}
+
+// rustModule returns the module path of a crate-relative file, or "" for
+// files that are not part of a module tree, such as build.rs
+func rustModule(rel string) string {
+	parts := strings.Split(strings.TrimSuffix(rel, ".rs"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "src":
+		parts = parts[1:]
+		// Each file in src/bin, or directory with a main.rs, is a binary
+		// crate of its own
+		if len(parts) > 1 && parts[0] == "bin" {
+			parts = parts[2:]
+		}
+	case "tests", "examples", "benches":
+		// Integration tests, examples and benchmarks are crates too, apart
+		// from modules they share such as tests/common/mod.rs
+		if len(parts) == 3 && parts[2] == "mod" {
+			parts = parts[1:2]
+		} else {
+			parts = parts[2:]
+		}
+	default:
+		return ""
+	}
+
+	if n := len(parts); n > 0 && parts[n-1] == "mod" {
+		parts = parts[:n-1]
+	}
+	if len(parts) == 1 && (parts[0] == "lib" || parts[0] == "main") {
+		parts = nil
+	}
+
+	for _, part := range parts {
+		if !identifier.MatchString(part) {
+			return ""
+		}
+	}
+	return strings.Join(append([]string{"crate"}, parts...), "::")
+}
+
+// find returns the crate containing dir: the nearest Cargo.toml with a
+// [package] table
+func (c *cargoCrates) find(dir string) *cargoCrate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.findLocked(dir)
+}
+
+func (c *cargoCrates) findLocked(dir string) *cargoCrate {
+	if crate, ok := c.crates[dir]; ok {
+		return crate
+	}
+
+	crate := readCargoCrate(dir)
+	if crate == nil {
+		if parent := filepath.Dir(dir); parent != dir {
+			crate = c.findLocked(parent)
+		}
+	}
+	c.crates[dir] = crate
+	return crate
+}
+
+// readCargoCrate reads the crate name from dir/Cargo.toml. The [lib] name
+// wins over the package name, as it does for code importing the crate.
+func readCargoCrate(dir string) *cargoCrate {
+	var pkgName, libName string
+	hasPackage := false
+	readConfigSections(filepath.Join(dir, "Cargo.toml"), false, func(section, key, value string) {
+		switch {
+		case section == "package":
+			hasPackage = true
+			if key == "name" {
+				pkgName = unquoteTOML(value)
+			}
+		case section == "lib" && key == "name":
+			libName = unquoteTOML(value)
+		}
+	})
+	if !hasPackage || pkgName == "" {
+		return nil
+	}
+
+	name := libName
+	if name == "" {
+		name = strings.ReplaceAll(pkgName, "-", "_")
+	}
+	return &cargoCrate{Name: name, Dir: dir}
+}
@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRustModule(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want string
+	}{
+		{"src/lib.rs", "crate"},
+		{"src/main.rs", "crate"},
+		{"src/config.rs", "crate::config"},
+		{"src/net/mod.rs", "crate::net"},
+		{"src/net/http/client.rs", "crate::net::http::client"},
+		{"src/net/lib.rs", "crate::net::lib"},
+		{"src/bin/tool.rs", "crate"},
+		{"src/bin/tool/main.rs", "crate"},
+		{"src/bin/tool/args.rs", "crate::args"},
+		{"tests/api.rs", "crate"},
+		{"tests/api/main.rs", "crate"},
+		{"tests/api/fixtures.rs", "crate::fixtures"},
+		{"tests/common/mod.rs", "crate::common"},
+		{"examples/demo.rs", "crate"},
+		{"benches/speed.rs", "crate"},
+		{"build.rs", ""},
+		{"lib.rs", ""},
+		{"scripts/gen.rs", ""},
+		{"src/not-an-ident.rs", ""},
+		{"src/2fast.rs", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			if got := rustModule(tt.rel); got != tt.want {
+				t.Errorf("rustModule(%q) = %q, want %q", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCargoCrates(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"Cargo.toml":                 "[workspace]\nmembers = [\"crates/*\"]\n",
+		"crates/core/Cargo.toml":     "[package]\nname = \"acme-core\"\nversion = \"0.1.0\"\n",
+		"crates/core/src/lib.rs":     "",
+		"crates/core/src/net/mod.rs": "",
+		"crates/renamed/Cargo.toml":  "[package]\nname = \"acme-renamed\"\n\n[lib]\nname = \"renamed_lib\"\n",
+		"crates/renamed/src/lib.rs":  "",
+		"tools/loose.rs":             "",
+	})
+
+	tests := []struct {
+		path     string
+		pkg, imp string
+	}{
+		{"crates/core/src/lib.rs", "acme_core", "crate"},
+		{"crates/core/src/net/mod.rs", "acme_core", "crate::net"},
+		{"crates/renamed/src/lib.rs", "renamed_lib", "crate"},
+		{"tools/loose.rs", "", ""},
+	}
+
+	p := newTestProcessor(t, root, "path")
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info := resolveRust(p, newSourceFile(filepath.Join(root, filepath.FromSlash(tt.path))))
+			if info.Package != tt.pkg || info.Import != tt.imp {
+				t.Errorf("resolveRust() = pkg %q import %q, want %q %q", info.Package, info.Import, tt.pkg, tt.imp)
+			}
+		})
+	}
+}
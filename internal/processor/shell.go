@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// makefileNames are the file names make reads, in the order GNU make tries them
+var makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// makeTargets finds the Makefile targets whose recipes run a script
+type makeTargets struct {
+	mu    sync.Mutex
+	rules map[string]map[string]string // Makefile -> Makefile-relative script -> target
+}
+
+func newMakeTargets() *makeTargets {
+	return &makeTargets{rules: make(map[string]map[string]string)}
+}
+
+// resolveShell names the package after the Makefile target that runs the
+// script, falling back to the directory name
+func resolveShell(p *Processor, f *sourceFile) packageInfo {
+	return packageInfo{Package: p.makeTargets.find(f.abs, p.paths.gitRoot)}
+}
+
+// find returns the first target of the nearest Makefile, at or above the
+// script's directory and not above root, whose recipe runs the script
+func (m *makeTargets) find(absPath, root string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for dir := filepath.Dir(absPath); isWithin(root, dir); dir = filepath.Dir(dir) {
+		for _, name := range makefileNames {
+			file := filepath.Join(dir, name)
+			if !fileExists(file) {
+				continue
+			}
+			rules, ok := m.rules[file]
+			if !ok {
+				rules = readMakeTargets(file)
+				m.rules[file] = rules
+			}
+			if target, ok := rules[relSlash(dir, absPath)]; ok {
+				return target
+			}
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return ""
+}
+
+// makeDirPrefixes name the Makefile's directory at the start of a path
+var makeDirPrefixes = []string{"./", "$(CURDIR)/", "${CURDIR}/", "$(PWD)/", "${PWD}/"}
+
+// readMakeTargets maps each script run by a recipe in the Makefile to the
+// first target that runs it. Paths in recipes are taken relative to the
+// Makefile's directory; variables other than CURDIR are not expanded.
+func readMakeTargets(file string) map[string]string {
+	lines, err := readLines(file)
+	if err != nil {
+		return nil
+	}
+
+	scripts := make(map[string]string)
+	target := ""
+	for _, line := range lines {
+		if strings.HasPrefix(line, "\t") {
+			if target == "" {
+				continue
+			}
+			// Recipe lines may start with the @, - and + modifiers
+			recipe := strings.TrimLeft(line, "\t @-+")
+			for _, word := range strings.FieldsFunc(recipe, isShellSeparator) {
+				word = strings.Trim(word, `"'()`)
+				for _, prefix := range makeDirPrefixes {
+					word = strings.TrimPrefix(word, prefix)
+				}
+				if _, seen := scripts[word]; !seen && word != "" {
+					scripts[word] = target
+				}
+			}
+			continue
+		}
+
+		// A rule line is "targets: prerequisites", but not an assignment
+		// such as "X := y" or "X ::= y"
+		head, _, ok := strings.Cut(line, ":")
+		if !ok || strings.ContainsAny(head, "=#") || strings.HasPrefix(line[len(head):], ":=") || strings.HasPrefix(line[len(head):], "::=") {
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				target = ""
+			}
+			continue
+		}
+		target = ""
+		for _, name := range strings.Fields(head) {
+			if !strings.HasPrefix(name, ".") && !strings.Contains(name, "%") {
+				target = name
+				break
+			}
+		}
+	}
+	return scripts
+}
+
+// isShellSeparator splits a recipe line into words
+func isShellSeparator(r rune) bool {
+	return r == ' ' || r == '\t' || r == ';' || r == '&' || r == '|'
+}
@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadMakeTargets(t *testing.T) {
+	makefile := `.PHONY: build test
+VERSION := 1.0
+OUT ::= bin
+
+# Build everything
+build: deps
+	@./scripts/build.sh $(VERSION)
+	-$(CURDIR)/scripts/tag.sh && ${PWD}/scripts/push.sh | tee log
+
+test lint:
+	+bash scripts/test.sh; sh -c "scripts/quoted.sh"
+
+release:: build
+	./scripts/build.sh
+	scripts/release.sh
+
+%.o: %.c
+	./scripts/pattern.sh
+
+LDFLAGS = -X main.version=$(VERSION)
+	./scripts/after-assignment.sh
+
+install:
+	# A comment in the recipe
+	./scripts/install.sh
+`
+	file := filepath.Join(t.TempDir(), "Makefile")
+	if err := os.WriteFile(file, []byte(makefile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readMakeTargets(file)
+	want := map[string]string{
+		"scripts/build.sh":   "build",
+		"scripts/tag.sh":     "build",
+		"scripts/push.sh":    "build",
+		"scripts/test.sh":    "test",
+		"scripts/quoted.sh":  "test",
+		"scripts/release.sh": "release",
+		"scripts/install.sh": "install",
+	}
+	for word, target := range want {
+		if got[word] != target {
+			t.Errorf("%q maps to %q, want %q", word, got[word], target)
+		}
+	}
+	for _, word := range []string{"scripts/pattern.sh", "scripts/after-assignment.sh"} {
+		if target, ok := got[word]; ok {
+			t.Errorf("%q maps to %q, want no target", word, target)
+		}
+	}
+}
+
+func TestMakeTargetsFind(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Makefile":           "deploy:\n\t./ops/deploy.sh\n",
+		"ops/deploy.sh":      "",
+		"ops/unused.sh":      "",
+		"svc/GNUmakefile":    "run:\n\t./start.sh\n",
+		"svc/Makefile":       "other:\n\t./start.sh\n",
+		"svc/start.sh":       "",
+		"svc/deep/nested.sh": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := newMakeTargets()
+	got := map[string]string{}
+	for _, name := range []string{"ops/deploy.sh", "ops/unused.sh", "svc/start.sh", "svc/deep/nested.sh"} {
+		got[name] = m.find(filepath.Join(root, filepath.FromSlash(name)), root)
+	}
+	want := map[string]string{
+		"ops/deploy.sh":      "deploy",
+		"ops/unused.sh":      "",
+		"svc/start.sh":       "run",
+		"svc/deep/nested.sh": "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("find() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,373 @@
+package processor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tsConfigs resolves TypeScript module specifiers from tsconfig.json files.
+// Parsed configs and the nearest config of each directory are cached.
+type tsConfigs struct {
+	mu      sync.Mutex
+	nearest map[string]string    // directory -> nearest tsconfig.json ("" if none)
+	configs map[string]*tsConfig // config file -> parsed config (nil if unreadable)
+}
+
+// tsConfig is the part of a tsconfig.json that decides how files are imported,
+// with extends already applied
+type tsConfig struct {
+	file       string
+	baseURL    string              // absolute, "" when unset
+	paths      map[string][]string // compilerOptions.paths
+	pathsBase  string              // directory paths entries are relative to
+	references []string            // absolute config files of project references
+}
+
+// tsConfigFile mirrors the JSON layout of tsconfig.json
+type tsConfigFile struct {
+	Extends         json.RawMessage `json:"extends"`
+	CompilerOptions struct {
+		BaseURL *string             `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+	References []struct {
+		Path string `json:"path"`
+	} `json:"references"`
+}
+
+func newTSConfigs() *tsConfigs {
+	return &tsConfigs{
+		nearest: make(map[string]string),
+		configs: make(map[string]*tsConfig),
+	}
+}
+
+// tsExtensions are stripped from file names to get module specifiers, longest
+// first so ".d.ts" wins over ".ts"
+var tsExtensions = []string{".d.mts", ".d.cts", ".d.ts", ".mts", ".cts", ".tsx", ".ts"}
+
+// resolveTypeScript names the package like resolveJavaScript and emits the
+// module specifier other code uses to import the file, from the paths or
+// baseUrl of the tsconfig.json that applies to it
+func resolveTypeScript(p *Processor, f *sourceFile) packageInfo {
+	info := resolveJavaScript(p, f)
+	info.Import = p.tsConfigs.specifier(f.abs)
+	return info
+}
+
+// specifier returns the non-relative module specifier for the TypeScript file
+// at absPath, or "" when no tsconfig.json maps it
+func (c *tsConfigs) specifier(absPath string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modules := tsModulePaths(absPath)
+	own, importers := c.candidates(filepath.Dir(absPath))
+	for _, cfg := range own {
+		if spec := cfg.specifier(modules, true); spec != "" {
+			return spec
+		}
+	}
+	for _, cfg := range importers {
+		if spec := cfg.specifier(modules, false); spec != "" {
+			return spec
+		}
+	}
+	return ""
+}
+
+// candidates lists the configs that may map a file in dir. own holds the
+// nearest tsconfig.json and the configs it references that sit at or above dir
+// (the tsconfig.app.json of a solution-style setup). importers holds ancestor
+// configs whose references include the nearest one, and the other projects
+// they reference, whose paths are how the rest of the solution imports it.
+func (c *tsConfigs) candidates(dir string) (own, importers []*tsConfig) {
+	file := c.nearestConfig(dir)
+	if file == "" {
+		return nil, nil
+	}
+	cfg := c.load(file)
+	if cfg == nil {
+		return nil, nil
+	}
+
+	own = []*tsConfig{cfg}
+	for _, ref := range cfg.references {
+		if refCfg := c.load(ref); refCfg != nil && isWithin(filepath.Dir(ref), dir) {
+			own = append(own, refCfg)
+		}
+	}
+
+	for dir := filepath.Dir(filepath.Dir(file)); ; {
+		up := c.nearestConfig(dir)
+		if up == "" {
+			break
+		}
+		if upCfg := c.load(up); upCfg != nil {
+			if slices.Contains(upCfg.references, file) {
+				importers = append(importers, upCfg)
+				for _, sibling := range upCfg.references {
+					if sibCfg := c.load(sibling); sibCfg != nil && sibling != file {
+						importers = append(importers, sibCfg)
+					}
+				}
+			}
+		}
+		next := filepath.Dir(filepath.Dir(up))
+		if next == filepath.Dir(up) {
+			break
+		}
+		dir = next
+	}
+	return own, importers
+}
+
+// nearestConfig returns the closest tsconfig.json at or above dir
+func (c *tsConfigs) nearestConfig(dir string) string {
+	if file, ok := c.nearest[dir]; ok {
+		return file
+	}
+
+	file := filepath.Join(dir, "tsconfig.json")
+	if !fileExists(file) {
+		file = ""
+		if parent := filepath.Dir(dir); parent != dir {
+			file = c.nearestConfig(parent)
+		}
+	}
+	c.nearest[dir] = file
+	return file
+}
+
+// load parses a config file and the configs it extends
+func (c *tsConfigs) load(file string) *tsConfig {
+	return c.loadExtending(file, map[string]bool{})
+}
+
+func (c *tsConfigs) loadExtending(file string, seen map[string]bool) *tsConfig {
+	if cfg, ok := c.configs[file]; ok {
+		return cfg
+	}
+	if seen[file] {
+		return nil // extends cycle
+	}
+	seen[file] = true
+
+	var cfg *tsConfig
+	defer func() { c.configs[file] = cfg }()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var raw tsConfigFile
+	if err := json.Unmarshal(stripJSONC(data), &raw); err != nil {
+		return nil
+	}
+
+	dir := filepath.Dir(file)
+	cfg = &tsConfig{file: file, pathsBase: dir}
+
+	// Later entries of an extends array override earlier ones, and the file
+	// itself overrides them all
+	for _, ext := range tsExtends(raw.Extends) {
+		base := c.resolveExtends(dir, ext, seen)
+		if base == nil {
+			continue
+		}
+		if base.baseURL != "" {
+			cfg.baseURL = base.baseURL
+		}
+		if base.paths != nil {
+			cfg.paths, cfg.pathsBase = base.paths, base.pathsBase
+		}
+	}
+
+	if raw.CompilerOptions.BaseURL != nil {
+		cfg.baseURL = filepath.Join(dir, filepath.FromSlash(*raw.CompilerOptions.BaseURL))
+	}
+	if raw.CompilerOptions.Paths != nil {
+		cfg.paths, cfg.pathsBase = raw.CompilerOptions.Paths, dir
+	}
+	// paths entries are relative to baseUrl when one is set
+	if cfg.baseURL != "" {
+		cfg.pathsBase = cfg.baseURL
+	}
+
+	for _, ref := range raw.References {
+		refFile := filepath.Join(dir, filepath.FromSlash(ref.Path))
+		if !strings.HasSuffix(refFile, ".json") {
+			refFile = filepath.Join(refFile, "tsconfig.json")
+		}
+		cfg.references = append(cfg.references, refFile)
+	}
+	return cfg
+}
+
+// resolveExtends loads the config named by an extends entry. Relative names
+// are resolved against dir and package names against node_modules.
+func (c *tsConfigs) resolveExtends(dir, name string, seen map[string]bool) *tsConfig {
+	var candidates []string
+	if strings.HasPrefix(name, ".") || filepath.IsAbs(name) {
+		candidates = []string{filepath.Join(dir, filepath.FromSlash(name))}
+	} else {
+		for d := dir; ; d = filepath.Dir(d) {
+			candidates = append(candidates, filepath.Join(d, "node_modules", filepath.FromSlash(name)))
+			if filepath.Dir(d) == d {
+				break
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, file := range []string{candidate, candidate + ".json", filepath.Join(candidate, "tsconfig.json")} {
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return c.loadExtending(file, seen)
+			}
+		}
+	}
+	return nil
+}
+
+// tsExtends decodes extends, which is a string or, since TypeScript 5.0, an
+// array of strings
+func tsExtends(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil {
+		return []string{one}
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err == nil {
+		return many
+	}
+	return nil
+}
+
+// specifier maps the module paths of a file through paths, then baseUrl when
+// useBaseURL is set. When several patterns match, the shortest specifier wins.
+func (cfg *tsConfig) specifier(modules []string, useBaseURL bool) string {
+	var best string
+	better := func(spec string) {
+		if spec != "" && (best == "" || len(spec) < len(best) || (len(spec) == len(best) && spec < best)) {
+			best = spec
+		}
+	}
+
+	patterns := make([]string, 0, len(cfg.paths))
+	for pattern := range cfg.paths {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		for _, target := range cfg.paths[pattern] {
+			target = filepath.Join(cfg.pathsBase, filepath.FromSlash(target))
+			for _, module := range modules {
+				better(matchTSPath(pattern, target, module))
+			}
+		}
+	}
+	if best != "" || !useBaseURL || cfg.baseURL == "" {
+		return best
+	}
+
+	for _, module := range modules {
+		if rel, err := filepath.Rel(cfg.baseURL, module); err == nil && !strings.HasPrefix(rel, "..") && rel != "." {
+			better(filepath.ToSlash(rel))
+		}
+	}
+	return best
+}
+
+// matchTSPath returns the specifier pattern gives for module when target, an
+// absolute paths entry, matches it. Entries hold at most one "*".
+func matchTSPath(pattern, target, module string) string {
+	target = trimTSExtension(target)
+	prefix, suffix, wild := strings.Cut(target, "*")
+	if !wild {
+		if target == module {
+			return pattern
+		}
+		return ""
+	}
+	if len(module) < len(prefix)+len(suffix) || !strings.HasPrefix(module, prefix) || !strings.HasSuffix(module, suffix) {
+		return ""
+	}
+	match := filepath.ToSlash(module[len(prefix) : len(module)-len(suffix)])
+	if match == "" {
+		return ""
+	}
+	return strings.Replace(pattern, "*", match, 1)
+}
+
+// tsModulePaths returns absPath without its extension, and its directory too
+// when the file is an index module
+func tsModulePaths(absPath string) []string {
+	module := trimTSExtension(absPath)
+	if filepath.Base(module) == "index" {
+		return []string{module, filepath.Dir(module)}
+	}
+	return []string{module}
+}
+
+func trimTSExtension(name string) string {
+	for _, ext := range tsExtensions {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
+// isWithin reports whether target is dir or inside it
+func isWithin(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// stripJSONC turns JSON with comments and trailing commas, as tsconfig.json
+// allows, into plain JSON
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch ch := data[i]; {
+		case ch == '"':
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			end := min(i+1, len(data))
+			out = append(out, data[start:end]...)
+		case ch == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			i--
+		case ch == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case ch == ']' || ch == '}':
+			trimmed := strings.TrimRight(string(out), " \t\r\n")
+			if strings.HasSuffix(trimmed, ",") {
+				out = append([]byte(trimmed[:len(trimmed)-1]), out[len(trimmed):]...)
+			}
+			out = append(out, ch)
+		default:
+			out = append(out, ch)
+		}
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", `{"a": 1}`, `{"a": 1}`},
+		{"line comment", "{\n  // note\n  \"a\": 1\n}", `{"a": 1}`},
+		{"block comment", `{/* note */"a": /* x */ 1}`, `{"a": 1}`},
+		{"multi-line block comment", "{\n/*\n * note\n */\n\"a\": 1}", `{"a": 1}`},
+		{"trailing commas", "{\"a\": [1, 2,\n],\n}", `{"a": [1, 2]}`},
+		{"trailing comma before comment", "{\"a\": 1, // last\n}", `{"a": 1}`},
+		{"comment markers in strings", `{"a": "// not a comment", "b": "/* nor this */"}`, `{"a": "// not a comment", "b": "/* nor this */"}`},
+		{"escaped quotes", `{"a": "say \"hi\" // still a string"}`, `{"a": "say \"hi\" // still a string"}`},
+		{"comma in string", `{"a": ["x,"]}`, `{"a": ["x,"]}`},
+		{"paths", `{"compilerOptions": {"paths": {"@app/*": ["src/*"],}}}`, `{"compilerOptions": {"paths": {"@app/*": ["src/*"]}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := stripJSONC([]byte(tt.in))
+			var got, want any
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("stripJSONC(%q) = %q, not JSON: %v", tt.in, out, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("stripJSONC(%q) = %s, want %s", tt.in, out, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripJSONCUnterminatedComment(t *testing.T) {
+	if got := string(stripJSONC([]byte(`{"a": 1 /* open`))); got != `{"a": 1 ` {
+		t.Errorf("stripJSONC() = %q", got)
+	}
+}
+
+func TestMatchTSPath(t *testing.T) {
+	abs := filepath.FromSlash
+	tests := []struct {
+		name                    string
+		pattern, target, module string
+		want                    string
+	}{
+		{"wildcard", "@app/*", "/repo/src/*", "/repo/src/util/date", "@app/util/date"},
+		{"wildcard with extension", "@app/*", "/repo/src/*.ts", "/repo/src/util", "@app/util"},
+		{"wildcard with suffix", "@gen/*", "/repo/gen/*/index", "/repo/gen/api/index", "@gen/api"},
+		{"empty match", "@app/*", "/repo/src/*", "/repo/src/", ""},
+		{"outside target", "@app/*", "/repo/src/*", "/repo/lib/util", ""},
+		{"suffix mismatch", "@gen/*", "/repo/gen/*/index", "/repo/gen/api/client", ""},
+		{"overlapping prefix and suffix", "@x/*", "/repo/ab*ba", "/repo/aba", ""},
+		{"exact", "config", "/repo/src/config.ts", "/repo/src/config", "config"},
+		{"exact mismatch", "config", "/repo/src/config.ts", "/repo/src/other", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchTSPath(tt.pattern, abs(tt.target), abs(tt.module)); got != tt.want {
+				t.Errorf("matchTSPath(%q, %q, %q) = %q, want %q", tt.pattern, tt.target, tt.module, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimTSExtension(t *testing.T) {
+	tests := map[string]string{
+		"a.ts":      "a",
+		"a.tsx":     "a",
+		"a.d.ts":    "a",
+		"a.mts":     "a",
+		"a.d.cts":   "a",
+		"a.test.ts": "a.test",
+		"a.js":      "a.js",
+	}
+	for in, want := range tests {
+		if got := trimTSExtension(in); got != want {
+			t.Errorf("trimTSExtension(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package annotator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the annotation schema version written by Format. Headers written
+// before the version field existed parse as version 0.
+const Version = 1
+
+// Marker introduces the annotation inside a comment
+const Marker = "codemap:"
+
+// Well-known field keys
+const (
+	KeyVersion     = "v"
+	KeyPath        = "path"
+	KeyPackage     = "pkg"
+	KeyPackagePath = "pkgpath"
+	KeyModule      = "module"
+	KeyMigration   = "migration"
+	KeyGoPackage   = "go_package"
+	KeyJavaPackage = "java_package"
+	KeyLanguage    = "lang"
+	KeyImport      = "import"
+	KeyBuild       = "build"
+	KeyTest        = "test"
+)
+
+var (
+	// ErrNoAnnotation is returned by Parse when the text has no codemap marker
+	ErrNoAnnotation = errors.New("no codemap annotation")
+	// ErrMalformed is returned by Parse when the annotation cannot be decoded
+	ErrMalformed = errors.New("malformed codemap annotation")
+)
+
+// Field is a key/value pair in an annotation
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Annotation is the structured content of a codemap header, such as
+//
+//	codemap: v=1;path=internal/cli/apply.go;pkg=cli;lang=Go
+//
+// Fields keep the order they are written in. Keys and values may hold any
+// text: Format escapes the characters that carry meaning in the header and
+// Parse reverses it, so Parse(Format(a)) returns a for any a with the current
+// Version and a path field.
+type Annotation struct {
+	Version int
+	Fields  []Field
+}
+
+// Get returns the value of the first field with the given key
+func (a Annotation) Get(key string) (string, bool) {
+	for _, f := range a.Fields {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set replaces the value of the first field with the given key, or appends the
+// field if there is none
+func (a *Annotation) Set(key, value string) {
+	for i, f := range a.Fields {
+		if f.Key == key {
+			a.Fields[i].Value = value
+			return
+		}
+	}
+	a.Fields = append(a.Fields, Field{Key: key, Value: value})
+}
+
+// Path returns the path field
+func (a Annotation) Path() string {
+	v, _ := a.Get(KeyPath)
+	return v
+}
+
+// Package returns the pkg field
+func (a Annotation) Package() string {
+	v, _ := a.Get(KeyPackage)
+	return v
+}
+
+// Language returns the lang field
+func (a Annotation) Language() string {
+	v, _ := a.Get(KeyLanguage)
+	return v
+}
+
+// Format renders a as header text, starting with Marker. A zero Version is
+// written as the current Version.
+func Format(a Annotation) string {
+	version := a.Version
+	if version == 0 {
+		version = Version
+	}
+
+	var b strings.Builder
+	b.WriteString(Marker)
+	b.WriteString(" ")
+	b.WriteString(KeyVersion + "=" + strconv.Itoa(version))
+	for _, f := range a.Fields {
+		b.WriteString(";")
+		b.WriteString(escape(f.Key))
+		b.WriteString("=")
+		b.WriteString(escape(f.Value))
+	}
+	return b.String()
+}
+
+// Parse decodes the annotation in s. Anything before Marker, such as the
+// comment opener, is ignored, as is trailing whitespace and a trailing block
+// comment terminator ("-->", "*/" or "*/}}").
+func Parse(s string) (Annotation, error) {
+	idx := strings.Index(s, Marker)
+	if idx < 0 {
+		return Annotation{}, ErrNoAnnotation
+	}
+	body := strings.TrimSpace(s[idx+len(Marker):])
+	for _, end := range blockCommentEnds {
+		if strings.HasSuffix(body, end) {
+			body = strings.TrimSpace(strings.TrimSuffix(body, end))
+			break
+		}
+	}
+
+	var a Annotation
+	for i, pair := range strings.Split(body, ";") {
+		rawKey, rawValue, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Annotation{}, fmt.Errorf("%w: field %q has no value", ErrMalformed, pair)
+		}
+		key, err := unescape(rawKey)
+		if err != nil {
+			return Annotation{}, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		value, err := unescape(rawValue)
+		if err != nil {
+			return Annotation{}, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+
+		if i == 0 && key == KeyVersion {
+			version, err := strconv.Atoi(value)
+			if err != nil || version < 1 {
+				return Annotation{}, fmt.Errorf("%w: invalid version %q", ErrMalformed, value)
+			}
+			if version > Version {
+				return Annotation{}, fmt.Errorf("%w: unsupported version %d", ErrMalformed, version)
+			}
+			a.Version = version
+			continue
+		}
+		a.Fields = append(a.Fields, Field{Key: key, Value: value})
+	}
+
+	if _, ok := a.Get(KeyPath); !ok {
+		return Annotation{}, fmt.Errorf("%w: missing %s field", ErrMalformed, KeyPath)
+	}
+	return a, nil
+}
+
+// blockCommentEnds are the block comment terminators a header may end with.
+// escape keeps them out of keys and values.
+var blockCommentEnds = []string{"-->", "*/}}", "*/"}
+
+// escape percent-encodes the characters that delimit fields, percent itself,
+// control characters that would break the header line, and leading or
+// trailing spaces that Parse would otherwise trim. The second character of
+// "--" and "*/" is encoded too, so a header cannot close the block comment it
+// sits in, and XML comments never contain "--".
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		edge := i == 0 || i == len(s)-1
+		closes := i > 0 && ((c == '-' && s[i-1] == '-') || (c == '/' && s[i-1] == '*'))
+		if c == '%' || c == ';' || c == '=' || c < 0x20 || c == 0x7f || (c == ' ' && edge) || closes {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// unescape reverses escape
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("truncated escape in %q", s)
+		}
+		c, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+	return b.String(), nil
+}
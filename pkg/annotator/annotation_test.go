@@ -0,0 +1,183 @@
+package annotator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"plain", "internal/cli/apply.go"},
+		{"empty", ""},
+		{"delimiters", "a;b=c"},
+		{"percent", "100%"},
+		{"escaped looking", "%3B"},
+		{"control characters", "a\tb\nc\r\x7f"},
+		{"edge spaces", " padded "},
+		{"inner spaces", "a b"},
+		{"unicode", "päckage/ファイル.go"},
+		{"double dash", "a--b"},
+		{"dash run", "---"},
+		{"xml comment end", "a-->b"},
+		{"block comment end", "a*/b"},
+		{"helm comment end", "a*/}}"},
+		{"trailing dash", "a-"},
+		{"trailing star", "a*"},
+		{"leading slash", "/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := Annotation{
+				Version: Version,
+				Fields: []Field{
+					{Key: KeyPath, Value: tt.value},
+					{Key: tt.value + "key", Value: tt.value},
+					{Key: KeyLanguage, Value: "Go"},
+				},
+			}
+			got, err := Parse(Format(want))
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", Format(want), err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse(Format(a)) = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseInComments(t *testing.T) {
+	a := Annotation{
+		Version: Version,
+		Fields: []Field{
+			{Key: KeyPath, Value: "a*/b-->c--"},
+			{Key: KeyLanguage, Value: "x*"},
+		},
+	}
+	header := Format(a)
+
+	tests := []struct {
+		name        string
+		start, end  string
+		wantClosing string
+	}{
+		{"line comment", "//", "", ""},
+		{"hash comment", "#", "", ""},
+		{"c block comment", "/*", "*/", "*/"},
+		{"xml comment", "<!--", "-->", "-->"},
+		{"helm comment", "{{/*", "*/}}", "*/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := tt.start + " " + header
+			if tt.end != "" {
+				line += " " + tt.end
+			}
+			if tt.wantClosing != "" && strings.Index(line, tt.wantClosing) != len(line)-len(tt.end) {
+				t.Errorf("%q closes its comment before the end", line)
+			}
+
+			got, err := Parse(line)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", line, err)
+			}
+			if !reflect.DeepEqual(got, a) {
+				t.Errorf("Parse(%q) = %#v, want %#v", line, got, a)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Annotation
+		want string
+	}{
+		{
+			name: "current version",
+			in:   Annotation{Fields: []Field{{KeyPath, "a.go"}, {KeyPackage, "main"}}},
+			want: "codemap: v=1;path=a.go;pkg=main",
+		},
+		{
+			name: "escapes",
+			in:   Annotation{Version: 1, Fields: []Field{{KeyPath, " a;b=c%--*/ "}}},
+			want: "codemap: v=1;path=%20a%3Bb%3Dc%25-%2D*%2F%20",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.in); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Annotation
+		wantErr error
+	}{
+		{
+			name: "unversioned",
+			in:   "// codemap: path=a.go;lang=Go",
+			want: Annotation{Fields: []Field{{KeyPath, "a.go"}, {KeyLanguage, "Go"}}},
+		},
+		{
+			name: "trailing whitespace",
+			in:   "# codemap: v=1;path=a.py \t",
+			want: Annotation{Version: 1, Fields: []Field{{KeyPath, "a.py"}}},
+		},
+		{name: "no marker", in: "// package main", wantErr: ErrNoAnnotation},
+		{name: "missing path", in: "// codemap: v=1;lang=Go", wantErr: ErrMalformed},
+		{name: "field without value", in: "// codemap: v=1;path", wantErr: ErrMalformed},
+		{name: "invalid version", in: "// codemap: v=x;path=a.go", wantErr: ErrMalformed},
+		{name: "zero version", in: "// codemap: v=0;path=a.go", wantErr: ErrMalformed},
+		{name: "future version", in: "// codemap: v=99;path=a.go", wantErr: ErrMalformed},
+		{name: "truncated escape", in: "// codemap: v=1;path=a%4", wantErr: ErrMalformed},
+		{name: "invalid escape", in: "// codemap: v=1;path=a%zz", wantErr: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeNeverClosesComments(t *testing.T) {
+	for _, s := range []string{"--", "---", "-->", "*/", "**/", "*/}}", "a--*/b"} {
+		got := escape(s)
+		for _, end := range append(blockCommentEnds, "--") {
+			if strings.Contains(got, end) {
+				t.Errorf("escape(%q) = %q, contains %q", s, got, end)
+			}
+		}
+		if back, err := unescape(got); err != nil || back != s {
+			t.Errorf("unescape(escape(%q)) = %q, %v", s, back, err)
+		}
+	}
+}
@@ -1,166 +1,244 @@
 package annotator
 
 import (
-	"bufio"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/krzko/codemap/internal/languages"
 )
 
-const (
-	annotationPattern = "codemap: path="
-)
-
 // DefaultAnnotator implements the Annotator interface
 type DefaultAnnotator struct {
-	languages map[string]languages.Language
+	preserveMtime bool
+	editorConfigs editorConfigs
 }
 
-func New() Annotator {
-	return &DefaultAnnotator{
-		languages: map[string]languages.Language{
-			".go":         &languages.GoLang{},
-			".py":         &languages.Python{},
-			".js":         &languages.JavaScript{},
-			".jsx":        &languages.JavaScript{},
-			".ts":         &languages.JavaScript{},
-			".tsx":        &languages.JavaScript{},
-			".dockerfile": &languages.Dockerfile{},
-			"":            &languages.Dockerfile{},
-		},
+type Option func(*DefaultAnnotator)
+
+// WithPreserveMtime keeps the modification time of rewritten files
+func WithPreserveMtime(preserve bool) Option {
+	return func(a *DefaultAnnotator) {
+		a.preserveMtime = preserve
 	}
 }
 
-func (a *DefaultAnnotator) AddAnnotation(info FileInfo) error {
-	lang, ok := a.languages[filepath.Ext(info.Path)]
+func New(opts ...Option) Annotator {
+	a := &DefaultAnnotator{}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+func (a *DefaultAnnotator) AddAnnotation(info FileInfo) (Result, error) {
+	change, err := a.PlanAnnotation(info)
+	if err != nil {
+		return 0, err
+	}
+
+	relPath, err := filepath.Rel(".", info.Path)
+	if err != nil {
+		relPath = info.Path
+	}
+
+	switch change.Result {
+	case Skipped:
+		log.Printf("Skipping file (%s): %s", change.Reason, relPath)
+		return Skipped, nil
+	case UpToDate:
+		log.Printf("Skipping file (up to date): %s", relPath)
+		return UpToDate, nil
+	}
+
+	if err := writeFile(info.Path, []byte(change.After), a.preserveMtime); err != nil {
+		return 0, fmt.Errorf("failed to write file %s: %v", info.Path, err)
+	}
+
+	if change.Result == Refreshed {
+		log.Printf("Refreshed annotations in: %s", relPath)
+	} else {
+		log.Printf("Added annotations to: %s", relPath)
+	}
+
+	return change.Result, nil
+}
+
+// PlanAnnotation works out what AddAnnotation would do to the file, without
+// writing it
+func (a *DefaultAnnotator) PlanAnnotation(info FileInfo) (*Change, error) {
+	lang, ok := languages.ForPath(info.Path)
 	if !ok {
-		return fmt.Errorf("unsupported file type: %s", info.Path)
+		return nil, fmt.Errorf("unsupported file type: %s", info.Path)
 	}
 
 	// Read the file content
 	content, err := os.ReadFile(info.Path)
 	if err != nil {
-		return fmt.Errorf("failed to read file %s: %v", info.Path, err)
+		return nil, fmt.Errorf("failed to read file %s: %v", info.Path, err)
+	}
+
+	change := &Change{Path: info.Path, Before: string(content), After: string(content)}
+	if info.SkipReason != "" {
+		change.Result, change.Reason = Skipped, info.SkipReason
+		return change, nil
 	}
 
-	// Check if annotation already exists
-	if a.hasAnnotationWithLang(string(content), lang) {
-		relPath, err := filepath.Rel(".", info.Path)
+	annotation := a.createAnnotation(lang, info)
+	props := a.editorConfigs.properties(info.Path)
+	reason := unsupportedEncoding(content, props["charset"])
+	if reason == "" && props["charset"] == "latin1" && !isASCII(annotation) {
+		reason = "non-ASCII annotation in a latin1 file"
+	}
+	if reason != "" {
+		change.Result, change.Reason = Skipped, reason
+		return change, nil
+	}
+
+	// Keep the byte order mark first and match the file's line endings
+	bom, body := splitBOM(string(content))
+	eol := detectEOL(body)
+	if eol == "" {
+		eol = editorConfigEOL(props["end_of_line"])
+	}
+
+	// Compare an existing header against the fresh one, and take it out if
+	// it is stale so the new header goes where headerIndex wants it
+	change.Result = Added
+	lines := splitLines(body)
+	if idx := findHeader(lines, lang); idx >= 0 {
+		change.Line = idx + 1
+		existing, err := Parse(trimEOL(lines[idx]))
 		if err != nil {
-			relPath = info.Path
+			change.ParseErr = err
+		} else {
+			change.Existing = &existing
+			if a.isCurrent(existing, info) {
+				change.Result = UpToDate
+				return change, nil
+			}
 		}
-		log.Printf("Skipping file (already annotated): %s", relPath)
-		return nil
+		lines = splitLines(removeLine(lines, idx))
+		change.Result = Refreshed
 	}
 
-	// Create annotation and write
-	annotation := a.createAnnotation(lang, info)
-	f, err := os.OpenFile(info.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open file %s: %v", info.Path, err)
+	// Put the annotation below any lines that must stay first
+	idx := headerIndex(lines, lang)
+	if change.Line == 0 {
+		change.Line = idx + 1
 	}
-	defer f.Close()
+	change.After = bom + insertLine(lines, idx, annotation, eol)
+	return change, nil
+}
 
-	if _, err := f.WriteString(annotation + string(content)); err != nil {
-		return fmt.Errorf("failed to write file %s: %v", info.Path, err)
+func (a *DefaultAnnotator) RemoveAnnotation(info FileInfo) error {
+	change, err := a.PlanRemoval(info)
+	if err != nil {
+		return err
 	}
 
 	relPath, err := filepath.Rel(".", info.Path)
 	if err != nil {
 		relPath = info.Path
 	}
-	log.Printf("Added annotations to: %s", relPath)
+
+	switch change.Result {
+	case Skipped:
+		log.Printf("Skipping file (%s): %s", change.Reason, relPath)
+		return nil
+	case Unannotated:
+		log.Printf("Skipping file (no annotations): %s", relPath)
+		return nil
+	}
+
+	// Write the file back without the header line
+	if err := writeFile(info.Path, []byte(change.After), a.preserveMtime); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", info.Path, err)
+	}
+
+	log.Printf("Removed annotations from: %s", relPath)
 
 	return nil
 }
 
-func (a *DefaultAnnotator) RemoveAnnotation(path string) error {
-	lang, ok := a.languages[filepath.Ext(path)]
+// PlanRemoval works out what RemoveAnnotation would do to the file, without
+// writing it
+func (a *DefaultAnnotator) PlanRemoval(info FileInfo) (*Change, error) {
+	path := info.Path
+	lang, ok := languages.ForPath(path)
 	if !ok {
-		return fmt.Errorf("unsupported file type: %s", path)
+		return nil, fmt.Errorf("unsupported file type: %s", path)
 	}
 
 	// Read the file
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !a.hasAnnotationWithLang(string(content), lang) {
-		relPath, err := filepath.Rel(".", path)
-		if err != nil {
-			relPath = path
-		}
-		log.Printf("Skipping file (no annotations): %s", relPath)
-		return nil
+	change := &Change{Path: path, Before: string(content), After: string(content)}
+	if info.SkipReason != "" {
+		change.Result, change.Reason = Skipped, info.SkipReason
+		return change, nil
 	}
 
-	// Process the file line by line
-	var lines []string
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
-	isFirstLine := true
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		// Skip only if it's the first line and contains our annotation
-		if isFirstLine && strings.Contains(line, annotationPattern) {
-			isFirstLine = false
-			continue
-		}
-		lines = append(lines, line)
-		isFirstLine = false
+	if reason := unsupportedEncoding(content, a.editorConfigs.properties(path)["charset"]); reason != "" {
+		change.Result, change.Reason = Skipped, reason
+		return change, nil
 	}
 
-	if err := scanner.Err(); err != nil {
-		return err
+	bom, body := splitBOM(string(content))
+	lines := splitLines(body)
+	idx := findHeader(lines, lang)
+	if idx < 0 {
+		change.Result = Unannotated
+		return change, nil
 	}
 
-	// Write the file back
-	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %v", path, err)
-	}
+	change.Result = Removed
+	change.After = bom + removeLine(lines, idx)
+	return change, nil
+}
 
-	relPath, err := filepath.Rel(".", path)
-	if err != nil {
-		relPath = path
+// createAnnotation returns the header line for info, without a terminator.
+// Languages without line comments get a block comment closed on the same line.
+func (a *DefaultAnnotator) createAnnotation(lang languages.Language, info FileInfo) string {
+	line := lang.CommentStart() + " " + Format(info.annotation())
+	if end := lang.CommentEnd(); end != "" {
+		line += " " + end
 	}
-	log.Printf("Removed annotations from: %s", relPath)
-
-	return nil
+	return line
 }
 
-func (a *DefaultAnnotator) createAnnotation(lang languages.Language, info FileInfo) string {
-	commentStart := lang.CommentStart()
-	return fmt.Sprintf("%s codemap: path=%s;pkg=%s;lang=%s\n",
-		commentStart,
-		info.Path,
-		info.PackageName,
-		info.Language)
+// isCurrent reports whether an existing header matches the one that would be
+// written for info. The path may be in any path mode.
+func (a *DefaultAnnotator) isCurrent(existing Annotation, info FileInfo) bool {
+	return len(info.Mismatches(existing)) == 0
 }
 
 // HasAnnotation checks if a file has a codemap annotation
-func (a *DefaultAnnotator) HasAnnotation(content string) bool {
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	// Only check the first line
-	if !scanner.Scan() {
+func (a *DefaultAnnotator) HasAnnotation(path string, content string) bool {
+	lang, ok := languages.ForPath(path)
+	if !ok {
 		return false
 	}
-	firstLine := scanner.Text()
-	return strings.Contains(firstLine, annotationPattern)
+	_, body := splitBOM(content)
+	return findHeader(splitLines(body), lang) >= 0
 }
 
-// hasAnnotationWithLang is an internal helper that checks for language-specific annotation
-func (a *DefaultAnnotator) hasAnnotationWithLang(content string, lang languages.Language) bool {
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	// Only check the first line
-	if !scanner.Scan() {
-		return false
+// StripAnnotation returns content without its codemap annotation
+func (a *DefaultAnnotator) StripAnnotation(path string, content string) string {
+	lang, ok := languages.ForPath(path)
+	if !ok {
+		return content
 	}
-	firstLine := scanner.Text()
-	commentStart := lang.CommentStart()
-	return strings.Contains(firstLine, commentStart+" "+annotationPattern)
-}
\ No newline at end of file
+	bom, body := splitBOM(content)
+	lines := splitLines(body)
+	if idx := findHeader(lines, lang); idx >= 0 {
+		return bom + removeLine(lines, idx)
+	}
+	return content
+}
@@ -0,0 +1,139 @@
+package annotator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlockCommentHeaders(t *testing.T) {
+	const pkg = "a*/b--c-->d*/}}"
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		line    int    // 0-based line the header goes on
+		want    string // the header line
+	}{
+		{
+			name:    "css",
+			file:    "style.css",
+			content: "/* codemap is a theme -- dark */\nbody { color: red; }\n",
+			want:    "/* codemap: v=1;path=style.css;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} */",
+		},
+		{
+			name:    "scss",
+			file:    "style.scss",
+			content: "@charset \"utf-8\";\n// line comment\n$x: 1; /* trailing */\n",
+			line:    1,
+			want:    "/* codemap: v=1;path=style.scss;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} */",
+		},
+		{
+			name:    "html",
+			file:    "index.html",
+			content: "<!DOCTYPE html>\n<!-- nav -- main -->\n<html></html>\n",
+			line:    1,
+			want:    "<!-- codemap: v=1;path=index.html;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} -->",
+		},
+		{
+			name:    "xml",
+			file:    "pom.xml",
+			content: "<?xml version=\"1.0\"?>\n<!-- a -- b -->\n<project/>\n",
+			line:    1,
+			want:    "<!-- codemap: v=1;path=pom.xml;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} -->",
+		},
+		{
+			name:    "markdown",
+			file:    "README.md",
+			content: "---\ntitle: a -- b */\n---\n<!-- toc -->\n# Title\n",
+			line:    3,
+			want:    "<!-- codemap: v=1;path=README.md;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} -->",
+		},
+		{
+			name:    "vue",
+			file:    "App.vue",
+			content: "<template>\n  <!-- a -- b -->\n</template>\n<script>\n/* c */\n</script>\n",
+			want:    "<!-- codemap: v=1;path=App.vue;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} -->",
+		},
+		{
+			name:    "svelte",
+			file:    "App.svelte",
+			content: "<script>\n  let x = 1 /* */\n</script>\n<!-- a -- b -->\n",
+			want:    "<!-- codemap: v=1;path=App.svelte;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} -->",
+		},
+		{
+			name:    "helm",
+			file:    "templates/pod.yaml",
+			content: "{{/* a */}}\nkind: Pod # -- b\n",
+			want:    "{{/* codemap: v=1;path=pod.yaml;pkg=a*%2Fb-%2Dc-%2D>d*%2F}} */}}",
+		},
+	}
+
+	a := &DefaultAnnotator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, filepath.FromSlash(tt.file))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.name == "helm" {
+				if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("apiVersion: v2\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			change, err := a.PlanAnnotation(testInfo(path, pkg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if change.Result != Added {
+				t.Fatalf("PlanAnnotation() = %v, want added", change.Result)
+			}
+			lines := strings.Split(change.After, "\n")
+			want := strings.Replace(tt.want, "%s", filepath.Base(path), 1)
+			want = strings.ReplaceAll(want, "%", "%")
+			if lines[tt.line] != want {
+				t.Fatalf("line %d = %q, want %q", tt.line, lines[tt.line], want)
+			}
+
+			// The comment is closed once, at the end of the header
+			header := lines[tt.line]
+			for _, end := range []string{"*/", "-->"} {
+				if i := strings.Index(header, end); i >= 0 && i+len(end) < len(header) && !strings.HasSuffix(header, "*/}}") {
+					t.Errorf("%q closes its comment early", header)
+				}
+			}
+			if strings.Contains(header[4:len(header)-4], "--") {
+				t.Errorf("%q contains -- inside the comment", header)
+			}
+
+			parsed, err := Parse(header)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", header, err)
+			}
+			if got := parsed.Package(); got != pkg {
+				t.Errorf("parsed package = %q, want %q", got, pkg)
+			}
+
+			if err := os.WriteFile(path, []byte(change.After), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if current, err := a.PlanAnnotation(testInfo(path, pkg)); err != nil || current.Result != UpToDate {
+				t.Errorf("PlanAnnotation() of the same fields = %v, %v, want up to date", current.Result, err)
+			}
+			removal, err := a.PlanRemoval(FileInfo{Path: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removal.Result != Removed || removal.After != tt.content {
+				t.Errorf("PlanRemoval() = %v %q, want removed %q", removal.Result, removal.After, tt.content)
+			}
+		})
+	}
+}
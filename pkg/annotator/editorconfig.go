@@ -0,0 +1,150 @@
+package annotator
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/glob"
+)
+
+// editorConfigSection is a [pattern] section of an .editorconfig file
+type editorConfigSection struct {
+	pattern glob.Glob
+	// baseName is set for patterns without a slash, which match the file
+	// name in any subdirectory
+	baseName   bool
+	properties map[string]string
+}
+
+// matches reports whether the section applies to rel, a slash-separated path
+// relative to the .editorconfig directory
+func (s editorConfigSection) matches(rel string) bool {
+	if s.baseName {
+		return s.pattern.Match(path.Base(rel))
+	}
+	return s.pattern.Match(rel)
+}
+
+// editorConfigFile is a parsed .editorconfig file
+type editorConfigFile struct {
+	dir      string
+	root     bool
+	sections []editorConfigSection
+}
+
+// editorConfigs looks up .editorconfig properties, caching parsed files by
+// directory
+type editorConfigs struct {
+	mu    sync.Mutex
+	files map[string]*editorConfigFile // nil for directories without one
+}
+
+// properties returns the .editorconfig properties that apply to path. Files
+// closer to path and later sections take precedence, as in the spec.
+func (ec *editorConfigs) properties(path string) map[string]string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+
+	// Collect files from the nearest directory up to a root = true file
+	var files []*editorConfigFile
+	for dir := filepath.Dir(abs); ; {
+		if f := ec.load(dir); f != nil {
+			files = append(files, f)
+			if f.root {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	props := make(map[string]string)
+	for i := len(files) - 1; i >= 0; i-- {
+		f := files[i]
+		rel, err := filepath.Rel(f.dir, abs)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, s := range f.sections {
+			if s.matches(rel) {
+				for k, v := range s.properties {
+					props[k] = v
+				}
+			}
+		}
+	}
+	return props
+}
+
+// load returns the parsed .editorconfig in dir, if there is one
+func (ec *editorConfigs) load(dir string) *editorConfigFile {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	if f, ok := ec.files[dir]; ok {
+		return f
+	}
+	if ec.files == nil {
+		ec.files = make(map[string]*editorConfigFile)
+	}
+
+	f := parseEditorConfig(dir)
+	ec.files[dir] = f
+	return f
+}
+
+// parseEditorConfig reads dir/.editorconfig. Property names and values are
+// lowercased, as the spec makes them case-insensitive.
+func parseEditorConfig(dir string) *editorConfigFile {
+	data, err := os.ReadFile(filepath.Join(dir, ".editorconfig"))
+	if err != nil {
+		return nil
+	}
+
+	f := &editorConfigFile{dir: dir}
+	var current *editorConfigSection
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = nil
+			pattern := line[1 : len(line)-1]
+			baseName := !strings.Contains(pattern, "/")
+			g, err := glob.Compile(strings.TrimPrefix(pattern, "/"), '/')
+			if err != nil {
+				continue
+			}
+			f.sections = append(f.sections, editorConfigSection{pattern: g, baseName: baseName, properties: make(map[string]string)})
+			current = &f.sections[len(f.sections)-1]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch {
+		case current != nil:
+			current.properties[key] = value
+		case key == "root":
+			f.root = value == "true"
+		}
+	}
+
+	return f
+}
@@ -0,0 +1,60 @@
+package annotator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditorConfigProperties(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".editorconfig": `root = true
+
+[*]
+end_of_line = lf
+
+[*.{py,txt}]
+charset = latin1
+
+[docs/*.md]
+end_of_line = crlf
+`,
+		"sub/.editorconfig": `[*.py]
+charset = utf-8
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path, key, want string
+	}{
+		{"a.py", "charset", "latin1"},
+		{"deep/er/a.py", "charset", "latin1"},
+		{"deep/er/a.txt", "charset", "latin1"},
+		{"sub/a.py", "charset", "utf-8"},
+		{"sub/a.txt", "charset", "latin1"},
+		{"a.go", "charset", ""},
+		{"deep/a.go", "end_of_line", "lf"},
+		{"docs/a.md", "end_of_line", "crlf"},
+		{"docs/more/a.md", "end_of_line", "lf"},
+	}
+
+	var ec editorConfigs
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			props := ec.properties(filepath.Join(root, filepath.FromSlash(tt.path)))
+			if got := props[tt.key]; got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
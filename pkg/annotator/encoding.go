@@ -0,0 +1,68 @@
+package annotator
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+const utf8BOM = "\xef\xbb\xbf"
+
+// splitBOM separates a leading UTF-8 byte order mark from content
+func splitBOM(content string) (bom, rest string) {
+	if strings.HasPrefix(content, utf8BOM) {
+		return utf8BOM, content[len(utf8BOM):]
+	}
+	return "", content
+}
+
+// detectEOL returns the terminator of the first line in content, or "" if
+// content is a single unterminated line
+func detectEOL(content string) string {
+	idx := strings.IndexByte(content, '\n')
+	switch {
+	case idx < 0:
+		return ""
+	case idx > 0 && content[idx-1] == '\r':
+		return "\r\n"
+	default:
+		return "\n"
+	}
+}
+
+// editorConfigEOL maps an .editorconfig end_of_line value to a terminator,
+// defaulting to "\n"
+func editorConfigEOL(value string) string {
+	if value == "crlf" {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// unsupportedEncoding explains why codemap cannot safely edit content, or
+// returns "" if it can. charset is the .editorconfig charset, if any.
+func unsupportedEncoding(content []byte, charset string) string {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xfe, 0xff}), bytes.HasPrefix(content, []byte{0xff, 0xfe}):
+		return "UTF-16 or UTF-32 encoded"
+	case charset == "utf-16be" || charset == "utf-16le":
+		return "charset is " + charset + " in .editorconfig"
+	case bytes.IndexByte(content, 0) >= 0:
+		return "binary content"
+	case !utf8.Valid(content):
+		return "not valid UTF-8"
+	case bytes.IndexByte(content, '\r') >= 0 && bytes.IndexByte(content, '\n') < 0:
+		return "CR line endings"
+	}
+	return ""
+}
+
+// isASCII reports whether s only holds 7-bit characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package annotator
+
+import (
+	"strings"
+
+	"github.com/krzko/codemap/internal/languages"
+)
+
+// splitLines splits content into lines, keeping each line's terminator so that
+// joining the result reproduces content exactly
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// trimEOL strips the line terminator from a line returned by splitLines
+func trimEOL(line string) string {
+	return strings.TrimRight(line, "\r\n")
+}
+
+// headerIndex returns the line the header should be inserted at: after the
+// leading run of lines the language needs to keep at the top of the file, such
+// as shebangs, encoding cookies and parser directives, or where a Placed
+// language puts it
+func headerIndex(lines []string, lang languages.Language) int {
+	if placed, ok := lang.(languages.Placed); ok {
+		trimmed := make([]string, len(lines))
+		for i, line := range lines {
+			trimmed[i] = trimEOL(line)
+		}
+		return min(max(placed.HeaderLine(trimmed), 0), len(lines))
+	}
+
+	i := 0
+	for i < len(lines) && lang.IsSpecialComment(trimEOL(lines[i])) {
+		i++
+	}
+	return i
+}
+
+// findHeader returns the line holding an existing header, or -1. The header is
+// looked for in the same place headerIndex would put it, and on the lines
+// above it for files annotated before the header was placed after them.
+func findHeader(lines []string, lang languages.Language) int {
+	if _, ok := lang.(languages.Placed); ok {
+		last := headerIndex(lines, lang)
+		for i := 0; i <= last && i < len(lines); i++ {
+			if isHeader(trimEOL(lines[i]), lang) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for i, line := range lines {
+		line = trimEOL(line)
+		if isHeader(line, lang) {
+			return i
+		}
+		if !lang.IsSpecialComment(line) {
+			break
+		}
+	}
+	return -1
+}
+
+// isHeader reports whether line is a codemap header in the language's comment
+// syntax. Malformed headers count, so they can be reported and replaced.
+func isHeader(line string, lang languages.Language) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), lang.CommentStart()+" "+Marker)
+}
+
+// insertLine inserts line, terminated by eol, before lines[i] and returns the
+// resulting content. After a last line without a terminator, the terminator
+// moves from that line to the header, so removeLine can restore the content.
+func insertLine(lines []string, i int, line, eol string) string {
+	var b strings.Builder
+	for _, l := range lines[:i] {
+		b.WriteString(l)
+	}
+	if i > 0 && !strings.HasSuffix(lines[i-1], "\n") {
+		b.WriteString(eol)
+		b.WriteString(line)
+		return b.String()
+	}
+	b.WriteString(line)
+	b.WriteString(eol)
+	for _, l := range lines[i:] {
+		b.WriteString(l)
+	}
+	return b.String()
+}
+
+// removeLine drops lines[i] and returns the resulting content. A header on
+// the last line without a terminator takes the one insertLine added to the
+// line before it along.
+func removeLine(lines []string, i int) string {
+	before := strings.Join(lines[:i], "")
+	if i > 0 && i == len(lines)-1 && !strings.HasSuffix(lines[i], "\n") {
+		before = strings.TrimSuffix(strings.TrimSuffix(before, "\n"), "\r")
+	}
+	return before + strings.Join(lines[i+1:], "")
+}
@@ -0,0 +1,182 @@
+package annotator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krzko/codemap/internal/languages"
+)
+
+// writeTemp writes content to a file with the given name in a new temporary
+// directory and returns its path
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testInfo returns the FileInfo of a file annotated with its name and pkg
+func testInfo(path, pkg string) FileInfo {
+	return FileInfo{Path: path, Fields: []Field{
+		{Key: KeyPath, Value: filepath.Base(path)},
+		{Key: KeyPackage, Value: pkg},
+	}}
+}
+
+func TestPlacement(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    int // 0-based line the header goes on
+	}{
+		{"empty file", "a.py", "", 0},
+		{"plain python", "a.py", "x = 1\n", 0},
+		{"shebang", "a.py", "#!/usr/bin/env python3\nx = 1\n", 1},
+		{"shebang and cookie", "a.py", "#!/usr/bin/env python3\n# -*- coding: utf-8 -*-\nx = 1\n", 2},
+		{"cookie after comment", "a.py", "# Copyright Acme\n# -*- coding: latin-1 -*-\nx = 1\n", 2},
+		{"cookie after blank line", "a.py", "\n# coding: latin-1\nx = 1\n", 2},
+		{"cookie in docstring", "a.py", "\"\"\"doc\n# coding: latin-1\n\"\"\"\n", 0},
+		{"cookie after code", "a.py", "x = 1\n# coding: latin-1\n", 0},
+		{"pylint directive", "a.py", "# pylint: disable=all\nx = 1\n", 1},
+		{"shell shebang", "run.sh", "#!/bin/sh\necho hi\n", 1},
+		{"shebang without newline", "run.sh", "#!/bin/sh", 1},
+		{"dockerfile directives", "Dockerfile", "# syntax=docker/dockerfile:1\n# escape=`\nFROM alpine\n", 2},
+		{"dockerfile comment", "Dockerfile", "# base image\nFROM alpine\n", 0},
+		{"php open tag", "a.php", "<?php\nnamespace Acme;\n", 1},
+		{"xml declaration", "a.xml", "<?xml version=\"1.0\"?>\n<a/>\n", 1},
+		{"html doctype", "a.html", "<!DOCTYPE html>\n<html></html>\n", 1},
+		{"markdown front matter", "a.md", "---\ntitle: x\n---\n# Title\n", 3},
+		{"toml front matter", "a.md", "+++\ntitle = \"x\"\n+++\nText\n", 3},
+		{"unterminated front matter", "a.md", "---\ntitle: x\n", 0},
+		{"yaml directive", "a.yaml", "%YAML 1.2\n---\nkey: value\n", 2},
+		{"yaml document marker", "a.yaml", "---\nkey: value\n", 1},
+		{"cloud-config", "user-data.yaml", "#cloud-config\npackages: [git]\n", 1},
+		{"jinja cloud-config", "user-data.yaml", "## template: jinja\n#cloud-config\nhostname: x\n", 2},
+		{"cloud-init include", "user-data.yml", "#include\nhttps://example.com/user-data\n", 1},
+		{"cloud-init boothook", "boothook.yaml", "#cloud-boothook\n#!/bin/sh\necho hi\n", 2},
+		{"cloud-config after comment", "user-data.yaml", "# generated\n#cloud-config\nhostname: x\n", 0},
+		{"yaml comment", "a.yaml", "# note\nkey: value\n", 0},
+		{"ruby magic comment", "a.rb", "# frozen_string_literal: true\nmodule Acme\nend\n", 1},
+		{"javascript pragmas", "a.js", "#!/usr/bin/env node\n// @ts-check\nlet x\n", 2},
+		{"open block pragma", "a.js", "/* eslint-disable\n */\nlet x\n", 0},
+		{"css charset", "a.css", "@charset \"utf-8\";\na {}\n", 1},
+		{"go build constraint", "a.go", "//go:build linux\n\npackage a\n", 0},
+		{"c include guard", "a.h", "#ifndef A_H\n#define A_H\n#endif\n", 0},
+		{"crlf", "a.py", "#!/usr/bin/env python3\r\nx = 1\r\n", 1},
+	}
+
+	a := &DefaultAnnotator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, tt.file, tt.content)
+			lang, ok := languages.ForPath(path)
+			if !ok {
+				t.Fatalf("no language for %s", tt.file)
+			}
+
+			change, err := a.PlanAnnotation(testInfo(path, "one"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if change.Result != Added || change.Line != tt.want+1 {
+				t.Fatalf("PlanAnnotation() = %v on line %d, want added on line %d", change.Result, change.Line, tt.want+1)
+			}
+			lines := splitLines(change.After)
+			if got := findHeader(lines, lang); got != tt.want {
+				t.Fatalf("header found on line %d, want %d in %q", got, tt.want, change.After)
+			}
+			if n := strings.Count(change.After, Marker); n != 1 {
+				t.Fatalf("%d headers in %q", n, change.After)
+			}
+
+			// Refreshing rewrites the header in the same place
+			if err := os.WriteFile(path, []byte(change.After), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			refresh, err := a.PlanAnnotation(testInfo(path, "two"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refresh.Result != Refreshed || refresh.Line != tt.want+1 {
+				t.Errorf("refresh = %v on line %d, want refreshed on line %d", refresh.Result, refresh.Line, tt.want+1)
+			}
+			if got := findHeader(splitLines(refresh.After), lang); got != tt.want {
+				t.Errorf("refreshed header on line %d, want %d", got, tt.want)
+			}
+			if current, err := a.PlanAnnotation(testInfo(path, "one")); err != nil || current.Result != UpToDate {
+				t.Errorf("PlanAnnotation() of the same fields = %v, %v, want up to date", current.Result, err)
+			}
+
+			// Removing the header restores the original content exactly
+			removal, err := a.PlanRemoval(FileInfo{Path: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removal.Result != Removed || removal.After != tt.content {
+				t.Errorf("PlanRemoval() = %v %q, want removed %q", removal.Result, removal.After, tt.content)
+			}
+		})
+	}
+}
+
+func TestPlacementUnterminatedLastLine(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"unterminated", "#!/bin/sh", "#!/bin/sh\n# codemap: v=1;path=run.sh;pkg=one"},
+		{"terminated", "#!/bin/sh\n", "#!/bin/sh\n# codemap: v=1;path=run.sh;pkg=one\n"},
+	}
+
+	a := &DefaultAnnotator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "run.sh", tt.content)
+			change, err := a.PlanAnnotation(testInfo(path, "one"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if change.After != tt.want {
+				t.Errorf("PlanAnnotation() = %q, want %q", change.After, tt.want)
+			}
+			if got := a.StripAnnotation(path, change.After); got != tt.content {
+				t.Errorf("StripAnnotation() = %q, want %q", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestFindHeader(t *testing.T) {
+	python, _ := languages.ByName("Python")
+	markdown, _ := languages.ByName("Markdown")
+
+	tests := []struct {
+		name    string
+		lang    languages.Language
+		content string
+		want    int
+	}{
+		{"first line", python, "# codemap: v=1;path=a.py\nx = 1\n", 0},
+		{"below shebang", python, "#!/usr/bin/env python3\n# codemap: v=1;path=a.py\n", 1},
+		{"above shebang", python, "# codemap: v=1;path=a.py\n#!/usr/bin/env python3\n", 0},
+		{"below code", python, "x = 1\n# codemap: v=1;path=a.py\n", -1},
+		{"malformed", python, "# codemap: broken\n", 0},
+		{"other comment", python, "# codemap is great\n", -1},
+		{"none", python, "x = 1\n", -1},
+		{"below front matter", markdown, "---\nt: x\n---\n<!-- codemap: v=1;path=a.md -->\n", 3},
+		{"in body", markdown, "# Title\n\n<!-- codemap: v=1;path=a.md -->\n", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findHeader(splitLines(tt.content), tt.lang); got != tt.want {
+				t.Errorf("findHeader() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,160 @@
 package annotator
 
+import "fmt"
+
 type FileInfo struct {
-	Path        string
-	Language    string
-	ImportPath  string
-	PackageName string
+	// Path is the location of the file on disk
+	Path string
+	// Fields are the annotation fields in the order they are written. The
+	// path field holds the path as it should appear in the annotation.
+	Fields []Field
+	// PathAliases are other spellings of the path field, such as the path
+	// under another path mode, that identify the same file
+	PathAliases []string
+	// SkipReason, when set, leaves the file alone and reports it as skipped
+	// for this reason
+	SkipReason string
+}
+
+// matchesPath reports whether p names this file under any path mode
+func (info FileInfo) matchesPath(p string) bool {
+	if p == info.Path {
+		return true
+	}
+	if want, ok := info.annotation().Get(KeyPath); ok && p == want {
+		return true
+	}
+	for _, alias := range info.PathAliases {
+		if p == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// annotation returns the header for info
+func (info FileInfo) annotation() Annotation {
+	return Annotation{Version: Version, Fields: info.Fields}
+}
+
+// Mismatches describes each way existing differs from the annotation that
+// would be written for info. It is empty when existing is up to date. The
+// path field matches when it names the file under any path mode.
+func (info FileInfo) Mismatches(existing Annotation) []string {
+	want := info.annotation()
+	var mismatches []string
+	if existing.Version != want.Version {
+		mismatches = append(mismatches, fmt.Sprintf("version is %d, want %d", existing.Version, want.Version))
+	}
+
+	for _, f := range want.Fields {
+		got, ok := existing.Get(f.Key)
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("missing %s field", f.Key))
+		case f.Key == KeyPath && info.matchesPath(got):
+		case got != f.Value:
+			mismatches = append(mismatches, fmt.Sprintf("%s is %q, want %q", f.Key, got, f.Value))
+		}
+	}
+	for _, f := range existing.Fields {
+		if _, ok := want.Get(f.Key); !ok {
+			mismatches = append(mismatches, fmt.Sprintf("unexpected %s field", f.Key))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		if len(existing.Fields) != len(want.Fields) {
+			return []string{"fields are repeated"}
+		}
+		for i, f := range want.Fields {
+			if existing.Fields[i].Key != f.Key {
+				return []string{"fields are out of order"}
+			}
+		}
+	}
+	return mismatches
+}
+
+// Result describes what AddAnnotation did to a file
+type Result int
+
+const (
+	// Added means the file had no header and one was added
+	Added Result = iota
+	// Refreshed means a stale header was rewritten
+	Refreshed
+	// UpToDate means the existing header was left as it was
+	UpToDate
+	// Skipped means the file was left alone because codemap cannot safely
+	// edit it, for example because of its encoding
+	Skipped
+	// Removed means an existing header was removed
+	Removed
+	// Unannotated means there was no header to remove
+	Unannotated
+)
+
+func (r Result) String() string {
+	switch r {
+	case Added:
+		return "added"
+	case Refreshed:
+		return "refreshed"
+	case UpToDate:
+		return "up to date"
+	case Skipped:
+		return "skipped"
+	case Removed:
+		return "removed"
+	case Unannotated:
+		return "unannotated"
+	default:
+		return "unknown"
+	}
+}
+
+// Change describes what annotating or cleaning a file does to it, computed
+// without writing the file
+type Change struct {
+	// Path is the location of the file on disk
+	Path string
+	// Result is the outcome for the file
+	Result Result
+	// Reason explains why the file was skipped
+	Reason string
+	// Before and After are the file content without and with the change
+	Before string
+	After  string
+	// Line is the 1-based line of the existing header, or of where a new
+	// header goes
+	Line int
+	// Existing is the header found in the file, if it could be parsed
+	Existing *Annotation
+	// ParseErr is set when the file has a header that cannot be parsed
+	ParseErr error
+}
+
+// Changed reports whether the change modifies the file
+func (c *Change) Changed() bool {
+	return c.Before != c.After
 }
 
 // Annotator interface defines the methods for file annotation handling
 type Annotator interface {
-	// AddAnnotation adds file structure information to the file
-	AddAnnotation(info FileInfo) error
-	// RemoveAnnotation removes existing annotation from the file
-	RemoveAnnotation(path string) error
-	// HasAnnotation checks if a file has a codemap annotation
-	HasAnnotation(content string) bool
-}
\ No newline at end of file
+	// AddAnnotation adds file structure information to the file, rewriting
+	// an existing header whose fields no longer match info
+	AddAnnotation(info FileInfo) (Result, error)
+	// PlanAnnotation computes the result of AddAnnotation without writing
+	PlanAnnotation(info FileInfo) (*Change, error)
+	// RemoveAnnotation removes existing annotation from the file. Only the
+	// Path and SkipReason of info are used.
+	RemoveAnnotation(info FileInfo) error
+	// PlanRemoval computes the result of RemoveAnnotation without writing
+	PlanRemoval(info FileInfo) (*Change, error)
+	// HasAnnotation checks if the content of the file at path has a codemap annotation
+	HasAnnotation(path string, content string) bool
+	// StripAnnotation returns the content of the file at path without its
+	// codemap annotation, or content unchanged if it has none
+	StripAnnotation(path string, content string) string
+}
@@ -0,0 +1,107 @@
+package annotator
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writeFile replaces the content of the file at path without ever leaving it
+// truncated: data goes to a temporary file in the same directory, which is
+// synced and renamed over the original. The original's permissions and
+// ownership carry over, and its modification time too if preserveMtime is set.
+// The file is rewritten in place instead when renaming would change more than
+// its content: when the ownership cannot be kept, such as for a writable file
+// owned by someone else, when the directory is not writable, and when the file
+// has other hard links, which would keep the old content.
+func writeFile(path string, data []byte, preserveMtime bool) (err error) {
+	// Replace the file a symlink points to rather than the link itself
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	if hardLinked(info) {
+		return writeInPlace(target, data, info, preserveMtime)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".codemap-*")
+	if err != nil {
+		return writeInPlace(target, data, info, preserveMtime)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = chown(tmp, info); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return writeInPlace(target, data, info, preserveMtime)
+	}
+	// Set the mode after chown, which clears the setuid and setgid bits
+	if err = tmp.Chmod(info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if preserveMtime {
+		if err = os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+
+	syncDir(filepath.Dir(target))
+	return nil
+}
+
+// writeInPlace truncates and rewrites the file at path, which keeps its owner
+// and permissions but leaves it partly written if codemap is interrupted
+func writeInPlace(path string, data []byte, info os.FileInfo, preserveMtime bool) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if preserveMtime {
+		return os.Chtimes(path, info.ModTime(), info.ModTime())
+	}
+	return nil
+}
+
+// syncDir flushes a directory entry change, such as a rename, to disk. Not
+// every platform supports it, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	_ = d.Sync()
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package annotator
+
+import "os"
+
+// chown is a no-op on platforms without Unix file ownership
+func chown(f *os.File, info os.FileInfo) error {
+	return nil
+}
+
+// hardLinked reports false where link counts are not available
+func hardLinked(info os.FileInfo) bool {
+	return false
+}
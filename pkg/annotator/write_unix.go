@@ -0,0 +1,31 @@
+//go:build unix
+
+package annotator
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown gives f the owner and group of the file described by info
+func chown(f *os.File, info os.FileInfo) error {
+	want, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	current, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if have, ok := current.Sys().(*syscall.Stat_t); ok && have.Uid == want.Uid && have.Gid == want.Gid {
+		return nil
+	}
+	return f.Chown(int(want.Uid), int(want.Gid))
+}
+
+// hardLinked reports whether the file described by info has other names,
+// which would keep the old content if it were replaced by a rename
+func hardLinked(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Nlink > 1
+}
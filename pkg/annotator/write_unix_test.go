@@ -0,0 +1,114 @@
+//go:build unix
+
+package annotator
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFileIsAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.py")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFile(path, []byte("new\n"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A rename puts a new file in place rather than truncating the old one
+	if os.SameFile(before, after) {
+		t.Error("file was rewritten in place, want it replaced by a rename")
+	}
+}
+
+func TestWriteFileSpecialModeBits(t *testing.T) {
+	tests := []os.FileMode{
+		0o755 | os.ModeSetuid,
+		0o755 | os.ModeSetgid,
+		0o755 | os.ModeSticky,
+		0o600,
+	}
+
+	for _, mode := range tests {
+		path := filepath.Join(t.TempDir(), "run.sh")
+		if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := info.Mode() // the sticky bit may not be settable on files
+
+		if err := writeFile(path, []byte("new\n"), false); err != nil {
+			t.Fatal(err)
+		}
+
+		if info, err := os.Stat(path); err != nil || info.Mode() != want {
+			t.Errorf("mode = %v, want %v", info.Mode(), want)
+		}
+	}
+}
+
+func TestWriteFileKeepsOwnership(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing file ownership needs root")
+	}
+	path := filepath.Join(t.TempDir(), "a.py")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(path, 65534, 65534); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFile(path, []byte("new\n"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := info.Sys().(*syscall.Stat_t); st.Uid != 65534 || st.Gid != 65534 {
+		t.Errorf("owner = %d:%d, want 65534:65534", st.Uid, st.Gid)
+	}
+}
+
+func TestWriteFileReadOnlyDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.py")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0o755)
+
+	if err := writeFile(path, []byte("new\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new\n" {
+		t.Errorf("content = %q, want %q", got, "new\n")
+	}
+}